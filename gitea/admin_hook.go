@@ -0,0 +1,104 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// AdminHookType selects the instance wide hooks to list
+type AdminHookType string
+
+const (
+	// AdminHookTypeSystem system hooks receive the events of all repositories
+	AdminHookTypeSystem AdminHookType = "system"
+	// AdminHookTypeDefault default hooks are copied to every new repository
+	AdminHookTypeDefault AdminHookType = "default"
+	// AdminHookTypeAll lists system and default hooks
+	AdminHookTypeAll AdminHookType = "all"
+)
+
+// AdminListHooksOptions options for listing system and default hooks
+type AdminListHooksOptions struct {
+	ListOptions
+	// Type is only supported by Gitea 1.23 and newer, older versions list the system hooks
+	Type AdminHookType
+}
+
+// QueryEncode turns options into querystring argument
+func (opt *AdminListHooksOptions) QueryEncode() string {
+	query := opt.getURLQuery()
+	if opt.Type != "" {
+		query.Add("type", string(opt.Type))
+	}
+	return query.Encode()
+}
+
+// AdminListHooks lists the system and default hooks
+func (c *Client) AdminListHooks(opt AdminListHooksOptions) ([]*Hook, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_19_0); err != nil {
+		return nil, nil, err
+	}
+	if opt.Type != "" {
+		if err := c.checkServerVersionGreaterThanOrEqual(version1_23_0); err != nil {
+			return nil, nil, err
+		}
+	}
+	opt.setDefaults()
+	hooks := make([]*Hook, 0, opt.PageSize)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("/admin/hooks?%s", opt.QueryEncode()), nil, nil, &hooks)
+	return hooks, resp, err
+}
+
+// AdminGetHook gets a system or default hook by id
+func (c *Client) AdminGetHook(id int64) (*Hook, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_19_0); err != nil {
+		return nil, nil, err
+	}
+	h := new(Hook)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("/admin/hooks/%d", id), nil, nil, h)
+	return h, resp, err
+}
+
+// AdminCreateHook creates a system hook, default hooks can only be created in the web interface
+func (c *Client) AdminCreateHook(opt CreateHookOption) (*Hook, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_19_0); err != nil {
+		return nil, nil, err
+	}
+	if err := opt.Validate(); err != nil {
+		return nil, nil, err
+	}
+	body, err := json.Marshal(&opt)
+	if err != nil {
+		return nil, nil, err
+	}
+	h := new(Hook)
+	resp, err := c.getParsedResponse("POST", "/admin/hooks", jsonHeader, bytes.NewReader(body), h)
+	return h, resp, err
+}
+
+// AdminEditHook modifies a system or default hook, with hook id and options
+func (c *Client) AdminEditHook(id int64, opt EditHookOption) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_19_0); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&opt)
+	if err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("PATCH", fmt.Sprintf("/admin/hooks/%d", id), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
+
+// AdminDeleteHook deletes a system or default hook, with hook id
+func (c *Client) AdminDeleteHook(id int64) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_19_0); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/admin/hooks/%d", id), nil, nil)
+	return resp, err
+}
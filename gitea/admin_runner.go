@@ -0,0 +1,28 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+// AdminGetRunnerRegistrationToken gets a token to register an instance wide runner
+func (c *Client) AdminGetRunnerRegistrationToken() (*RegistrationToken, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_21_0); err != nil {
+		return nil, nil, err
+	}
+	return c.getRegistrationToken("/admin/runners/registration-token")
+}
+
+// AdminListActionRunners lists all runners of the instance, including those of organizations and repositories
+func (c *Client) AdminListActionRunners(opt ListActionRunnersOptions) (*ActionRunnersResponse, *Response, error) {
+	return c.listActionRunners("/admin/actions/runners", opt)
+}
+
+// AdminGetActionRunner gets a runner by id
+func (c *Client) AdminGetActionRunner(runnerID int64) (*ActionRunner, *Response, error) {
+	return c.getActionRunner("/admin/actions/runners", runnerID)
+}
+
+// AdminDeleteActionRunner deletes a runner by id
+func (c *Client) AdminDeleteActionRunner(runnerID int64) (*Response, error) {
+	return c.deleteActionRunner("/admin/actions/runners", runnerID)
+}
@@ -0,0 +1,222 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// maxCacheableBodySize is the largest response body stored in the cache,
+// bigger responses (e.g. file downloads) are streamed and not cached
+const maxCacheableBodySize = 1 << 20
+
+// CacheEntry is a stored response of a GET request
+type CacheEntry struct {
+	ETag         string
+	LastModified string
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+}
+
+// Cache stores responses of GET requests, so they can be revalidated with
+// conditional requests and served from the cache if the server answers 304 Not Modified.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+	Delete(key string)
+}
+
+// SetCache is an option for NewClient to cache responses of GET requests using ETag and Last-Modified
+func SetCache(cache Cache) ClientOption {
+	return func(client *Client) error {
+		client.mutex.Lock()
+		client.cache = cache
+		client.mutex.Unlock()
+		return nil
+	}
+}
+
+// SetCacheKeyFunc is an option for NewClient to add the credentials middlewares authenticate
+// requests with to the keys of cached responses. keyFunc is called before the middlewares
+// and returns an identifier of these credentials, e.g. the user a middleware signs requests for.
+// Responses of clients with middlewares are only cached once a key func is set, as they may
+// otherwise be served to requests made as another user. Middlewares which do not change
+// authentication, like the one of the otel package, can use a func returning "".
+func SetCacheKeyFunc(keyFunc func(req *http.Request) string) ClientOption {
+	return func(client *Client) error {
+		client.mutex.Lock()
+		client.cacheKeyFunc = keyFunc
+		client.mutex.Unlock()
+		return nil
+	}
+}
+
+// MemoryCache is an in-memory Cache evicting the least recently used entries
+type MemoryCache struct {
+	maxEntries int
+	mutex      sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// NewMemoryCache creates a MemoryCache holding up to maxEntries responses
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Get returns the entry stored for key
+func (m *MemoryCache) Get(key string) (*CacheEntry, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	m.lru.MoveToFront(elem)
+	return elem.Value.(*memoryCacheItem).entry, true
+}
+
+// Set stores the entry for key, evicting the least recently used entry if the cache is full
+func (m *MemoryCache) Set(key string, entry *CacheEntry) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if elem, ok := m.entries[key]; ok {
+		elem.Value.(*memoryCacheItem).entry = entry
+		m.lru.MoveToFront(elem)
+		return
+	}
+	m.entries[key] = m.lru.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for m.maxEntries > 0 && m.lru.Len() > m.maxEntries {
+		oldest := m.lru.Back()
+		m.lru.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Delete removes the entry stored for key
+func (m *MemoryCache) Delete(key string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if elem, ok := m.entries[key]; ok {
+		m.lru.Remove(elem)
+		delete(m.entries, key)
+	}
+}
+
+// cacheKey identifies a request, including the credentials used, as responses depend on permissions.
+// scope identifies the credentials not set on the request yet, see cacheScope.
+func cacheKey(req *http.Request, scope string) string {
+	hash := sha256.New()
+	for _, h := range []string{"Authorization", "Sudo", "X-Gitea-Otp"} {
+		_, _ = io.WriteString(hash, req.Header.Get(h)+"\n")
+	}
+	_, _ = io.WriteString(hash, scope)
+	return req.Method + " " + req.URL.String() + " " + hex.EncodeToString(hash.Sum(nil))
+}
+
+// cacheScope returns the credentials of a request which are added after the cache lookup:
+// the key of the http signature and what keyFunc returns for the middlewares
+func cacheScope(signer *HTTPSign, keyFunc func(*http.Request) string, req *http.Request) string {
+	var scope string
+	if signer != nil {
+		// the fingerprint of a certificate covers its principals
+		scope = ssh.FingerprintSHA256(signer.PublicKey())
+	}
+	if keyFunc != nil {
+		scope += "\n" + keyFunc(req)
+	}
+	return scope
+}
+
+// cacheRequest makes a GET request conditional if a response is already cached
+func cacheRequest(cache Cache, req *http.Request, signer *HTTPSign, keyFunc func(*http.Request) string) (string, *CacheEntry) {
+	if cache == nil || req.Method != http.MethodGet {
+		return "", nil
+	}
+	key := cacheKey(req, cacheScope(signer, keyFunc, req))
+	entry, ok := cache.Get(key)
+	if !ok {
+		return key, nil
+	}
+	if entry.ETag != "" && req.Header.Get("If-None-Match") == "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+	if entry.LastModified != "" && req.Header.Get("If-Modified-Since") == "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+	return key, entry
+}
+
+// cacheResponse serves a 304 Not Modified response from the cache and stores new cacheable responses.
+// It reports whether the returned response was served from the cache.
+func cacheResponse(cache Cache, key string, entry *CacheEntry, resp *http.Response) (*http.Response, bool) {
+	if key == "" {
+		return resp, false
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		_ = resp.Body.Close()
+		header := entry.Header.Clone()
+		for k, v := range resp.Header {
+			header[k] = v
+		}
+		return &http.Response{
+			Status:        http.StatusText(entry.StatusCode),
+			StatusCode:    entry.StatusCode,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+			ContentLength: int64(len(entry.Body)),
+			Request:       resp.Request,
+		}, true
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, false
+	}
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		cache.Delete(key)
+		return resp, false
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCacheableBodySize+1))
+	if err != nil || len(data) > maxCacheableBodySize {
+		// too big or broken, hand the stream on without caching it
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		return resp, false
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	cache.Set(key, &CacheEntry{
+		ETag:         etag,
+		LastModified: lastModified,
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		Body:         data,
+	})
+	return resp, false
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestCache(t *testing.T) {
+	var full, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("Authorization") == "token a" {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		_, _ = w.Write([]byte(`{"id": 1, "name": "repo"}`))
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache(10)
+	c, err := NewClient(server.URL, SetGiteaVersion(""), SetToken("a"), SetCache(cache))
+	assert.NoError(t, err)
+
+	repo, resp, err := c.GetRepo("owner", "repo")
+	assert.NoError(t, err)
+	assert.False(t, resp.CacheHit)
+	assert.EqualValues(t, "repo", repo.Name)
+
+	repo, resp, err = c.GetRepo("owner", "repo")
+	assert.NoError(t, err)
+	assert.True(t, resp.CacheHit)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, "repo", repo.Name)
+	assert.Equal(t, 1, full)
+	assert.Equal(t, 1, notModified)
+
+	// other credentials do not share the cached response
+	c2, err := NewClient(server.URL, SetGiteaVersion(""), SetToken("b"), SetCache(cache))
+	assert.NoError(t, err)
+	_, resp, err = c2.GetRepo("owner", "repo")
+	assert.NoError(t, err)
+	assert.False(t, resp.CacheHit)
+	assert.Equal(t, 2, full)
+}
+
+func TestCacheCredentials(t *testing.T) {
+	var full int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		_, _ = w.Write([]byte(`{"id": 1, "name": "repo"}`))
+	}))
+	defer server.Close()
+	getRepo := func(c *Client) bool {
+		_, resp, err := c.GetRepo("owner", "repo")
+		assert.NoError(t, err)
+		return resp.CacheHit
+	}
+	newSigner := func() *HTTPSign {
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		signer, err := ssh.NewSignerFromKey(key)
+		assert.NoError(t, err)
+		return &HTTPSign{Signer: signer}
+	}
+
+	// http signatures are applied after the cache lookup, the key of the signer is part of the cache key
+	cache := NewMemoryCache(10)
+	alice, err := NewClient(server.URL, SetGiteaVersion(""), SetCache(cache))
+	assert.NoError(t, err)
+	alice.httpsigner = newSigner()
+	bob, err := NewClient(server.URL, SetGiteaVersion(""), SetCache(cache))
+	assert.NoError(t, err)
+	bob.httpsigner = newSigner()
+	assert.False(t, getRepo(alice))
+	assert.True(t, getRepo(alice))
+	assert.False(t, getRepo(bob))
+	assert.Equal(t, 2, full)
+
+	// credentials set by middlewares are unknown, they must be covered by a key func
+	auth := func(user string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				req.Header.Set("X-Api-User", user)
+				return next(req)
+			}
+		}
+	}
+	cache = NewMemoryCache(10)
+	c, err := NewClient(server.URL, SetGiteaVersion(""), SetCache(cache), UseMiddleware(auth("alice")))
+	assert.NoError(t, err)
+	assert.False(t, getRepo(c))
+	assert.False(t, getRepo(c), "clients with middlewares do not cache by default")
+
+	alice, err = NewClient(server.URL, SetGiteaVersion(""), SetCache(cache), UseMiddleware(auth("alice")),
+		SetCacheKeyFunc(func(*http.Request) string { return "alice" }))
+	assert.NoError(t, err)
+	bob, err = NewClient(server.URL, SetGiteaVersion(""), SetCache(cache), UseMiddleware(auth("bob")),
+		SetCacheKeyFunc(func(*http.Request) string { return "bob" }))
+	assert.NoError(t, err)
+	assert.False(t, getRepo(alice))
+	assert.True(t, getRepo(alice))
+	assert.False(t, getRepo(bob))
+	assert.Equal(t, 6, full)
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", &CacheEntry{ETag: "a"})
+	cache.Set("b", &CacheEntry{ETag: "b"})
+	_, ok := cache.Get("a")
+	assert.True(t, ok)
+	cache.Set("c", &CacheEntry{ETag: "c"})
+
+	_, ok = cache.Get("b")
+	assert.False(t, ok, "least recently used entry should be evicted")
+	_, ok = cache.Get("a")
+	assert.True(t, ok)
+	cache.Delete("a")
+	_, ok = cache.Get("a")
+	assert.False(t, ok)
+}
@@ -12,13 +12,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
-
-	"github.com/hashicorp/go-version"
 )
 
 var jsonHeader = http.Header{"content-type": []string{"application/json"}}
@@ -30,21 +29,27 @@ func Version() string {
 
 // Client represents a thread-safe Gitea API client.
 type Client struct {
-	url            string
-	accessToken    string
-	username       string
-	password       string
-	otp            string
-	sudo           string
-	userAgent      string
-	debug          bool
-	httpsigner     *HTTPSign
-	client         *http.Client
-	ctx            context.Context
-	mutex          sync.RWMutex
-	serverVersion  *version.Version
-	getVersionOnce sync.Once
-	ignoreVersion  bool // only set by SetGiteaVersion so don't need a mutex lock
+	url           string
+	accessToken   string
+	username      string
+	password      string
+	otp           string
+	sudo          string
+	userAgent     string
+	logger        *slog.Logger
+	logBodyLimit  int
+	httpsigner    *HTTPSign
+	client        *http.Client
+	ctx           context.Context
+	mutex         sync.RWMutex
+	versionCache  *serverVersionCache // shared with the copies made by WithContext
+	ignoreVersion bool                // only set by SetGiteaVersion so don't need a mutex lock
+	retryPolicy   RetryPolicy
+	rateLimiter   *rateLimiter // shared with the copies made by WithContext
+	cache         Cache
+	cacheKeyFunc  func(*http.Request) string
+	middlewares   []Middleware
+	callHooks     []CallHook
 }
 
 // Response represents the gitea response
@@ -55,6 +60,10 @@ type Response struct {
 	PrevPage  int
 	NextPage  int
 	LastPage  int
+
+	// CacheHit is true if the response was served from the cache set by SetCache,
+	// after the server confirmed it is still up to date
+	CacheHit bool
 }
 
 // ClientOption are functions used to init a new client
@@ -64,9 +73,10 @@ type ClientOption func(*Client) error
 // Usage of all gitea.Client methods is concurrency-safe.
 func NewClient(url string, options ...ClientOption) (*Client, error) {
 	client := &Client{
-		url:    strings.TrimSuffix(url, "/"),
-		client: &http.Client{},
-		ctx:    context.Background(),
+		url:          strings.TrimSuffix(url, "/"),
+		client:       &http.Client{},
+		ctx:          context.Background(),
+		versionCache: &serverVersionCache{},
 	}
 	for _, opt := range options {
 		if err := opt(client); err != nil {
@@ -200,12 +210,46 @@ func SetContext(ctx context.Context) ClientOption {
 }
 
 // SetContext set default context witch is used for http requests
+// To use a different context per request, use WithContext instead.
 func (c *Client) SetContext(ctx context.Context) {
 	c.mutex.Lock()
 	c.ctx = ctx
 	c.mutex.Unlock()
 }
 
+// WithContext returns a shallow copy of the client which uses ctx for all its requests.
+// The copy is cheap to create and shares the http.Client and the cached server version
+// with the original client, changing options on the copy does not affect the original.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return &Client{
+		url:           c.url,
+		accessToken:   c.accessToken,
+		username:      c.username,
+		password:      c.password,
+		otp:           c.otp,
+		sudo:          c.sudo,
+		userAgent:     c.userAgent,
+		logger:        c.logger,
+		logBodyLimit:  c.logBodyLimit,
+		httpsigner:    c.httpsigner,
+		client:        c.client,
+		ctx:           ctx,
+		versionCache:  c.versionCache,
+		ignoreVersion: c.ignoreVersion,
+		retryPolicy:   c.retryPolicy,
+		rateLimiter:   c.rateLimiter,
+		cache:         c.cache,
+		cacheKeyFunc:  c.cacheKeyFunc,
+		middlewares:   c.middlewares,
+		callHooks:     c.callHooks,
+	}
+}
+
 // SetSudo is an option for NewClient to set sudo header
 func SetSudo(sudo string) ClientOption {
 	return func(client *Client) error {
@@ -236,16 +280,6 @@ func (c *Client) SetUserAgent(userAgent string) {
 	c.mutex.Unlock()
 }
 
-// SetDebugMode is an option for NewClient to enable debug mode
-func SetDebugMode() ClientOption {
-	return func(client *Client) error {
-		client.mutex.Lock()
-		client.debug = true
-		client.mutex.Unlock()
-		return nil
-	}
-}
-
 func newResponse(r *http.Response) *Response {
 	response := &Response{Response: r}
 	response.parseLinkHeader()
@@ -297,48 +331,112 @@ func (r *Response) parseLinkHeader() {
 	}
 }
 
-func (c *Client) getWebResponse(method, path string, body io.Reader) ([]byte, *Response, error) {
+func (c *Client) getWebResponse(method, path string, body io.Reader) (data []byte, response *Response, err error) {
 	c.mutex.RLock()
-	debug := c.debug
-	if debug {
-		fmt.Printf("%s: %s\nBody: %v\n", method, c.url+path, body)
-	}
-	req, err := http.NewRequestWithContext(c.ctx, method, c.url+path, body)
-
+	logger := requestLogger{logger: c.logger, bodyLimit: c.logBodyLimit}
+	limiter := c.rateLimiter
+	middlewares := c.middlewares
+	hooks := c.callHooks
+	ctx := c.ctx
 	client := c.client // client ref can change from this point on so safe it
 	c.mutex.RUnlock()
 
+	ctx, endOperation := startOperation(ctx, hooks, method, path)
+	defer func() { endOperation(response, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	resp, err := client.Do(req)
+	roundTrip := chainMiddlewares(middlewares, c.transport(client, limiter, logger, false, nil, 1))
+	resp, err := roundTrip(req)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
-	if debug {
-		fmt.Printf("Response: %v\n\n", resp)
-	}
+	data, err = io.ReadAll(resp.Body)
 
 	return data, newResponse(resp), err
 }
 
-func (c *Client) doRequest(method, path string, header http.Header, body io.Reader) (*Response, error) {
+func (c *Client) doRequest(method, path string, header http.Header, body io.Reader) (response *Response, err error) {
 	c.mutex.RLock()
-	debug := c.debug
-	if debug {
-		var bodyStr string
-		if body != nil {
-			bs, _ := io.ReadAll(body)
-			body = bytes.NewReader(bs)
-			bodyStr = string(bs)
+	logger := requestLogger{logger: c.logger, bodyLimit: c.logBodyLimit}
+	retry := c.retryPolicy
+	limiter := c.rateLimiter
+	cache := c.cache
+	cacheKeyFunc := c.cacheKeyFunc
+	signer := c.httpsigner
+	middlewares := c.middlewares
+	hooks := c.callHooks
+	ctx := c.ctx
+	client := c.client // client ref can change from this point on so safe it
+	c.mutex.RUnlock()
+
+	if len(middlewares) > 0 && cacheKeyFunc == nil {
+		// middlewares may authenticate requests in a way the cache key does not cover
+		cache = nil
+	}
+
+	ctx, endOperation := startOperation(ctx, hooks, method, path)
+	defer func() { endOperation(response, err) }()
+
+	if !retry.allowsMethod(method) {
+		retry.MaxAttempts = 1
+	}
+
+	// buffer the body so it can be logged and sent again on retries
+	var bodyData []byte
+	if body != nil && (logger.logBodies(ctx) || retry.enabled()) {
+		if bodyData, err = io.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		if bodyData != nil {
+			body = bytes.NewReader(bodyData)
+		}
+		req, err := c.newRequest(ctx, method, path, header, body)
+		if err != nil {
+			return nil, err
+		}
+
+		key, cached := cacheRequest(cache, req, signer, cacheKeyFunc)
+		roundTrip := chainMiddlewares(middlewares, c.transport(client, limiter, logger, true, bodyData, attempt))
+		resp, err := roundTrip(req)
+		cacheHit := false
+		if err == nil {
+			resp, cacheHit = cacheResponse(cache, key, cached, resp)
+		}
+
+		wait, ok := retry.nextRetry(attempt, resp, err)
+		if !ok || attempt >= retry.MaxAttempts {
+			if err != nil {
+				return nil, err
+			}
+			response = newResponse(resp)
+			response.CacheHit = cacheHit
+			return response, nil
+		}
+		if resp != nil {
+			// drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
 		}
-		fmt.Printf("%s: %s\nHeader: %v\nBody: %s\n", method, c.url+"/api/v1"+path, header, bodyStr)
 	}
-	req, err := http.NewRequestWithContext(c.ctx, method, c.url+"/api/v1"+path, body)
+}
+
+// newRequest builds a request against the API and applies the client's authentication,
+// except for the http signature which is applied after the middlewares
+func (c *Client) newRequest(ctx context.Context, method, path string, header http.Header, body io.Reader) (*http.Request, error) {
+	c.mutex.RLock()
+	req, err := http.NewRequestWithContext(ctx, method, c.url+"/api/v1"+path, body)
 	if err != nil {
 		c.mutex.RUnlock()
 		return nil, err
@@ -358,34 +456,16 @@ func (c *Client) doRequest(method, path string, header http.Header, body io.Read
 	if len(c.userAgent) != 0 {
 		req.Header.Set("User-Agent", c.userAgent)
 	}
-
-	client := c.client // client ref can change from this point on so safe it
 	c.mutex.RUnlock()
 
 	for k, v := range header {
 		req.Header[k] = v
 	}
-
-	if c.httpsigner != nil {
-		err = c.SignRequest(req)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	if debug {
-		fmt.Printf("Response: %v\n\n", resp)
-	}
-
-	return newResponse(resp), nil
+	return req, nil
 }
 
 // Converts a response for a HTTP status code indicating an error condition
-// (non-2XX) to a well-known error value and response body. For non-problematic
+// (non-2XX) to an *APIError and response body. For non-problematic
 // (2XX) status codes nil will be returned. Note that on a non-2XX response, the
 // response body stream will have been read and, hence, is closed on return.
 func statusCodeToErr(resp *Response) (body []byte, err error) {
@@ -403,22 +483,29 @@ func statusCodeToErr(resp *Response) (body []byte, err error) {
 		return nil, fmt.Errorf("body read on HTTP error %d: %v", resp.StatusCode, err)
 	}
 
-	// Try to unmarshal and get an error message
-	errMap := make(map[string]interface{})
-	if err = json.Unmarshal(data, &errMap); err != nil {
-		// when the JSON can't be parsed, data was probably empty or a
-		// plain string, so we try to return a helpful error anyway
-		path := resp.Request.URL.Path
-		method := resp.Request.Method
-		return data, fmt.Errorf("Unknown API Error: %d\nRequest: '%s' with '%s' method and '%s' body", resp.StatusCode, path, method, string(data))
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       data,
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.URL = resp.Request.URL.String()
 	}
 
-	if msg, ok := errMap["message"]; ok {
-		return data, fmt.Errorf("%v", msg)
+	// Try to unmarshal and get an error message, when the JSON can't be parsed,
+	// data was probably empty or a plain string and is kept as raw body only
+	errMsg := struct {
+		Message string   `json:"message"`
+		URL     string   `json:"url"`
+		Errors  []string `json:"errors"`
+	}{}
+	if json.Unmarshal(data, &errMsg) == nil {
+		apiErr.Message = errMsg.Message
+		apiErr.DocURL = errMsg.URL
+		apiErr.Errors = errMsg.Errors
 	}
 
-	// If no error message, at least give status and data
-	return data, fmt.Errorf("%s: %s", resp.Status, string(data))
+	return data, apiErr
 }
 
 func (c *Client) getResponseReader(method, path string, header http.Header, body io.Reader) (io.ReadCloser, *Response, error) {
@@ -466,16 +553,6 @@ func (c *Client) getParsedResponse(method, path string, header http.Header, body
 	return resp, json.Unmarshal(data, obj)
 }
 
-func (c *Client) getStatusCode(method, path string, header http.Header, body io.Reader) (int, *Response, error) {
-	resp, err := c.doRequest(method, path, header, body)
-	if err != nil {
-		return -1, resp, err
-	}
-	defer resp.Body.Close()
-
-	return resp.StatusCode, resp, nil
-}
-
 // pathEscapeSegments escapes segments of a path while not escaping forward slash
 func pathEscapeSegments(path string) string {
 	slice := strings.Split(path, "/")
@@ -5,9 +5,13 @@
 package gitea
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -33,3 +37,29 @@ func TestParsedPaging(t *testing.T) {
 	assert.Equal(t, 3, resp.NextPage)
 	assert.Equal(t, 4, resp.LastPage)
 }
+
+func TestWithContext(t *testing.T) {
+	var versionCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/version":
+			atomic.AddInt32(&versionCalls, 1)
+			_, _ = w.Write([]byte(`{"version": "1.22.0"}`))
+		default:
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err = c.WithContext(ctx).GetRepo("owner", "repo")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the copy shares the server version with the original client
+	assert.NoError(t, c.WithContext(context.Background()).checkServerVersionGreaterThanOrEqual(version1_22_0))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&versionCalls))
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors to match an APIError against with errors.Is
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+)
+
+// APIError is returned for every response of the API with a non-2XX status code
+type APIError struct {
+	StatusCode int
+	Method     string
+	URL        string
+	// Message is the message returned by the server, if any
+	Message string
+	// Errors contains details, e.g. which fields failed validation
+	Errors []string
+	// DocURL points to the API documentation of the failed endpoint
+	DocURL string
+	// Body is the raw response body
+	Body []byte
+}
+
+// Error fulfills error
+func (e *APIError) Error() string {
+	if e.Message != "" {
+		if len(e.Errors) != 0 {
+			return fmt.Sprintf("%s: %s", e.Message, strings.Join(e.Errors, ", "))
+		}
+		return e.Message
+	}
+	if len(e.Body) != 0 {
+		return fmt.Sprintf("Unknown API Error: %d\nRequest: '%s' with '%s' method and '%s' body", e.StatusCode, e.URL, e.Method, string(e.Body))
+	}
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// Is matches the error against the sentinel errors based on the status code
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrValidation:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/orgs/missing/actions/secrets/test":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "The target couldn't be found.", "url": "https://gitea.com/api/swagger", "errors": []}`))
+		case "/api/v1/repos/o/r/issues":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message": "invalid input", "errors": ["title is required"]}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`access denied`))
+		}
+	}))
+	defer server.Close()
+	c, err := NewClient(server.URL, SetGiteaVersion(""))
+	assert.NoError(t, err)
+
+	resp, err := c.CreateOrgActionSecret("missing", CreateSecretOption{Name: "test", Data: "test"})
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrForbidden))
+	assert.EqualValues(t, "The target couldn't be found.", err.Error())
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	var apiErr *APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, "PUT", apiErr.Method)
+		assert.Equal(t, server.URL+"/api/v1/orgs/missing/actions/secrets/test", apiErr.URL)
+		assert.Equal(t, "https://gitea.com/api/swagger", apiErr.DocURL)
+	}
+
+	_, _, err = c.CreateIssue("o", "r", CreateIssueOption{Title: "test"})
+	assert.True(t, errors.Is(err, ErrValidation))
+	assert.EqualValues(t, "invalid input: title is required", err.Error())
+
+	_, _, err = c.GetRepo("o", "r")
+	assert.True(t, errors.Is(err, ErrForbidden))
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, []byte("access denied"), apiErr.Body)
+	}
+}
@@ -0,0 +1,261 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package giteatest
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+type secret struct {
+	name    string
+	data    string
+	created time.Time
+}
+
+// ActionSecret returns the data of a Gitea Actions secret, which the API never returns.
+// The secret belongs to a repository, or if repoName is empty to the organization or user owner.
+// It panics if the owner or repository does not exist.
+func (s *Server) ActionSecret(owner, repoName, name string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var secrets map[string]*secret
+	switch {
+	case repoName != "":
+		secrets = s.seededRepo(owner, repoName).secrets
+	case s.orgs[strings.ToLower(owner)] != nil:
+		secrets = s.orgs[strings.ToLower(owner)].secrets
+	case s.users[strings.ToLower(owner)] != nil:
+		secrets = s.users[strings.ToLower(owner)].secrets
+	default:
+		must(errNotFound())
+	}
+	sec := secrets[strings.ToUpper(name)]
+	if sec == nil {
+		return "", false
+	}
+	return sec.data, true
+}
+
+// secretNamePattern is the pattern of secret and variable names, they are upper cased like Gitea does
+var secretNamePattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+func checkActionName(name string) error {
+	if !secretNamePattern.MatchString(name) || strings.HasPrefix(name, "GITEA_") || strings.HasPrefix(name, "GITHUB_") {
+		return errorf(http.StatusBadRequest, "invalid name %q", name)
+	}
+	return nil
+}
+
+// secretScope returns the secrets the request is for, if doer may manage them
+type secretScope func(r *http.Request, doer *user) (*map[string]*secret, error)
+
+func (s *Server) registerActionRoutes() {
+	repoSecrets := func(r *http.Request, doer *user) (*map[string]*secret, error) {
+		rp, err := s.repoFor(r, doer, true)
+		if err != nil {
+			return nil, err
+		}
+		return &rp.secrets, nil
+	}
+	orgSecrets := func(r *http.Request, doer *user) (*map[string]*secret, error) {
+		o, err := s.orgFor(r, doer, true)
+		if err != nil {
+			return nil, err
+		}
+		return &o.secrets, nil
+	}
+	userSecrets := func(_ *http.Request, doer *user) (*map[string]*secret, error) {
+		if err := requireUser(doer); err != nil {
+			return nil, err
+		}
+		return &doer.secrets, nil
+	}
+	s.handle("GET", "/repos/{owner}/{repo}/actions/secrets", listSecrets(repoSecrets))
+	s.registerSecrets("/repos/{owner}/{repo}/actions/secrets/{secretname}", repoSecrets)
+	s.handle("GET", "/orgs/{org}/actions/secrets", listSecrets(orgSecrets))
+	s.registerSecrets("/orgs/{org}/actions/secrets/{secretname}", orgSecrets)
+	s.registerSecrets("/user/actions/secrets/{secretname}", userSecrets)
+
+	s.registerVariables("/repos/{owner}/{repo}/actions/variables", func(r *http.Request, doer *user) (*map[string]*gitea.Variable, int64, int64, error) {
+		rp, err := s.repoFor(r, doer, true)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return &rp.vars, 0, rp.ID, nil
+	})
+	s.registerVariables("/orgs/{org}/actions/variables", func(r *http.Request, doer *user) (*map[string]*gitea.Variable, int64, int64, error) {
+		o, err := s.orgFor(r, doer, true)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return &o.vars, o.ID, 0, nil
+	})
+	s.registerVariables("/user/actions/variables", func(_ *http.Request, doer *user) (*map[string]*gitea.Variable, int64, int64, error) {
+		if err := requireUser(doer); err != nil {
+			return nil, 0, 0, err
+		}
+		return &doer.vars, doer.ID, 0, nil
+	})
+}
+
+func listSecrets(scope secretScope) handler {
+	return func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		secrets, err := scope(r, doer)
+		if err != nil {
+			return err
+		}
+		result := make([]*gitea.Secret, 0, len(*secrets))
+		for _, sec := range *secrets {
+			// the data is not returned like Gitea does
+			result = append(result, &gitea.Secret{Name: sec.name, Created: sec.created})
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+		return writeJSON(w, http.StatusOK, paginate(w, r, result))
+	}
+}
+
+func (s *Server) registerSecrets(pattern string, scope secretScope) {
+	s.handle("PUT", pattern, func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		secrets, err := scope(r, doer)
+		if err != nil {
+			return err
+		}
+		name := strings.ToUpper(r.PathValue("secretname"))
+		if err := checkActionName(name); err != nil {
+			return err
+		}
+		var opt gitea.CreateSecretOption
+		if err := decode(r, &opt); err != nil {
+			return err
+		}
+		if opt.Data == "" {
+			return errorf(http.StatusUnprocessableEntity, "data is required")
+		}
+		if *secrets == nil {
+			*secrets = make(map[string]*secret)
+		}
+		if sec := (*secrets)[name]; sec != nil {
+			sec.data = opt.Data
+			return writeJSON(w, http.StatusNoContent, nil)
+		}
+		(*secrets)[name] = &secret{name: name, data: opt.Data, created: now()}
+		return writeJSON(w, http.StatusCreated, nil)
+	})
+	s.handle("DELETE", pattern, func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		secrets, err := scope(r, doer)
+		if err != nil {
+			return err
+		}
+		name := strings.ToUpper(r.PathValue("secretname"))
+		if (*secrets)[name] == nil {
+			return errNotFound()
+		}
+		delete(*secrets, name)
+		return writeJSON(w, http.StatusNoContent, nil)
+	})
+}
+
+// variableScope returns the variables the request is for, if doer may manage them,
+// and the owner and repository id of them
+type variableScope func(r *http.Request, doer *user) (vars *map[string]*gitea.Variable, ownerID, repoID int64, err error)
+
+func (s *Server) registerVariables(prefix string, scope variableScope) {
+	variableFor := func(r *http.Request, doer *user) (*map[string]*gitea.Variable, *gitea.Variable, error) {
+		vars, _, _, err := scope(r, doer)
+		if err != nil {
+			return nil, nil, err
+		}
+		v := (*vars)[strings.ToUpper(r.PathValue("variablename"))]
+		if v == nil {
+			return nil, nil, errNotFound()
+		}
+		return vars, v, nil
+	}
+	s.handle("GET", prefix, func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		vars, _, _, err := scope(r, doer)
+		if err != nil {
+			return err
+		}
+		result := make([]*gitea.Variable, 0, len(*vars))
+		for _, v := range *vars {
+			cp := *v
+			result = append(result, &cp)
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+		return writeJSON(w, http.StatusOK, paginate(w, r, result))
+	})
+	s.handle("GET", prefix+"/{variablename}", func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		_, v, err := variableFor(r, doer)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, http.StatusOK, v)
+	})
+	s.handle("POST", prefix+"/{variablename}", func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		vars, ownerID, repoID, err := scope(r, doer)
+		if err != nil {
+			return err
+		}
+		name := strings.ToUpper(r.PathValue("variablename"))
+		if err := checkActionName(name); err != nil {
+			return err
+		}
+		var opt gitea.CreateVariableOption
+		if err := decode(r, &opt); err != nil {
+			return err
+		}
+		if opt.Value == "" {
+			return errorf(http.StatusUnprocessableEntity, "value is required")
+		}
+		if (*vars)[name] != nil {
+			return errorf(http.StatusConflict, "variable %s already exists", name)
+		}
+		if *vars == nil {
+			*vars = make(map[string]*gitea.Variable)
+		}
+		(*vars)[name] = &gitea.Variable{OwnerID: ownerID, RepoID: repoID, Name: name, Data: opt.Value}
+		return writeJSON(w, http.StatusCreated, nil)
+	})
+	s.handle("PUT", prefix+"/{variablename}", func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		vars, v, err := variableFor(r, doer)
+		if err != nil {
+			return err
+		}
+		var opt gitea.UpdateVariableOption
+		if err := decode(r, &opt); err != nil {
+			return err
+		}
+		if opt.Value == "" {
+			return errorf(http.StatusUnprocessableEntity, "value is required")
+		}
+		if name := strings.ToUpper(opt.Name); name != "" && name != v.Name {
+			if err := checkActionName(name); err != nil {
+				return err
+			}
+			if (*vars)[name] != nil {
+				return errorf(http.StatusConflict, "variable %s already exists", name)
+			}
+			delete(*vars, v.Name)
+			v.Name = name
+			(*vars)[name] = v
+		}
+		v.Data = opt.Value
+		return writeJSON(w, http.StatusNoContent, nil)
+	})
+	s.handle("DELETE", prefix+"/{variablename}", func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		vars, v, err := variableFor(r, doer)
+		if err != nil {
+			return err
+		}
+		delete(*vars, v.Name)
+		return writeJSON(w, http.StatusNoContent, nil)
+	})
+}
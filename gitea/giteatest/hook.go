@@ -0,0 +1,204 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package giteatest
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// SeedHook creates a webhook of a repository.
+// It panics if the repository does not exist or the options are invalid.
+func (s *Server) SeedHook(owner, repoName string, opt gitea.CreateHookOption) *gitea.Hook {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rp := s.seededRepo(owner, repoName)
+	h, err := s.createHook(&rp.hooks, opt)
+	must(err)
+	return renderHook(h)
+}
+
+// SeedOrgHook creates a webhook of an organization.
+// It panics if the organization does not exist or the options are invalid.
+func (s *Server) SeedOrgHook(orgName string, opt gitea.CreateHookOption) *gitea.Hook {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	o := s.orgs[strings.ToLower(orgName)]
+	if o == nil {
+		must(errNotFound())
+	}
+	h, err := s.createHook(&o.hooks, opt)
+	must(err)
+	return renderHook(h)
+}
+
+func (s *Server) createHook(hooks *[]*gitea.Hook, opt gitea.CreateHookOption) (*gitea.Hook, error) {
+	if opt.Type == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "type is required")
+	}
+	if opt.Config["url"] == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "Missing config option: url")
+	}
+	if (opt.Type == gitea.HookTypeGitea || opt.Type == gitea.HookTypeGogs) && opt.Config["content_type"] == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "Missing config option: content_type")
+	}
+	events := opt.Events
+	if len(events) == 0 {
+		events = []string{"push"}
+	}
+	h := &gitea.Hook{
+		ID:      s.nextID(),
+		Type:    string(opt.Type),
+		Config:  maps.Clone(opt.Config),
+		Events:  slices.Clone(events),
+		Active:  opt.Active,
+		Created: now(),
+		Updated: now(),
+
+		BranchFilter:        opt.BranchFilter,
+		AuthorizationHeader: opt.AuthorizationHeader,
+	}
+	*hooks = append(*hooks, h)
+	return h, nil
+}
+
+// renderHook copies a hook, the secret is not returned like Gitea does
+func renderHook(h *gitea.Hook) *gitea.Hook {
+	cp := *h
+	cp.Config = maps.Clone(h.Config)
+	delete(cp.Config, "secret")
+	cp.Events = slices.Clone(h.Events)
+	return &cp
+}
+
+// hookScope returns the hooks the request is for, if doer may manage them
+type hookScope func(r *http.Request, doer *user) (*[]*gitea.Hook, error)
+
+func (s *Server) registerHookRoutes() {
+	repoHooks := func(r *http.Request, doer *user) (*[]*gitea.Hook, error) {
+		rp, err := s.repoFor(r, doer, true)
+		if err != nil {
+			return nil, err
+		}
+		return &rp.hooks, nil
+	}
+	s.registerHooks("/repos/{owner}/{repo}/hooks", repoHooks)
+	// test deliveries are accepted but not sent
+	s.handle("POST", "/repos/{owner}/{repo}/hooks/{id}/tests", func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		if _, _, err := hookFor(r, doer, repoHooks); err != nil {
+			return err
+		}
+		return writeJSON(w, http.StatusNoContent, nil)
+	})
+	s.registerHooks("/orgs/{org}/hooks", func(r *http.Request, doer *user) (*[]*gitea.Hook, error) {
+		o, err := s.orgFor(r, doer, true)
+		if err != nil {
+			return nil, err
+		}
+		return &o.hooks, nil
+	})
+	s.registerHooks("/admin/hooks", func(_ *http.Request, doer *user) (*[]*gitea.Hook, error) {
+		if err := requireAdmin(doer); err != nil {
+			return nil, err
+		}
+		return &s.systemHooks, nil
+	})
+	s.registerHooks("/user/hooks", func(_ *http.Request, doer *user) (*[]*gitea.Hook, error) {
+		if err := requireUser(doer); err != nil {
+			return nil, err
+		}
+		return &doer.hooks, nil
+	})
+}
+
+func (s *Server) registerHooks(prefix string, scope hookScope) {
+	s.handle("GET", prefix, func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		hooks, err := scope(r, doer)
+		if err != nil {
+			return err
+		}
+		result := make([]*gitea.Hook, 0, len(*hooks))
+		for _, h := range paginate(w, r, *hooks) {
+			result = append(result, renderHook(h))
+		}
+		return writeJSON(w, http.StatusOK, result)
+	})
+	s.handle("POST", prefix, func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		hooks, err := scope(r, doer)
+		if err != nil {
+			return err
+		}
+		var opt gitea.CreateHookOption
+		if err := decode(r, &opt); err != nil {
+			return err
+		}
+		h, err := s.createHook(hooks, opt)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, http.StatusCreated, renderHook(h))
+	})
+	s.handle("GET", prefix+"/{id}", func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		_, h, err := hookFor(r, doer, scope)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, http.StatusOK, renderHook(h))
+	})
+	s.handle("PATCH", prefix+"/{id}", func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		_, h, err := hookFor(r, doer, scope)
+		if err != nil {
+			return err
+		}
+		var opt gitea.EditHookOption
+		if err := decode(r, &opt); err != nil {
+			return err
+		}
+		for key, value := range opt.Config {
+			h.Config[key] = value
+		}
+		if opt.Events != nil {
+			h.Events = slices.Clone(opt.Events)
+		}
+		if opt.Active != nil {
+			h.Active = *opt.Active
+		}
+		h.BranchFilter = opt.BranchFilter
+		if opt.AuthorizationHeader != "" {
+			h.AuthorizationHeader = opt.AuthorizationHeader
+		}
+		h.Updated = now()
+		return writeJSON(w, http.StatusOK, renderHook(h))
+	})
+	s.handle("DELETE", prefix+"/{id}", func(w http.ResponseWriter, r *http.Request, doer *user) error {
+		hooks, h, err := hookFor(r, doer, scope)
+		if err != nil {
+			return err
+		}
+		*hooks = slices.DeleteFunc(*hooks, func(other *gitea.Hook) bool { return other == h })
+		return writeJSON(w, http.StatusNoContent, nil)
+	})
+}
+
+func hookFor(r *http.Request, doer *user, scope hookScope) (*[]*gitea.Hook, *gitea.Hook, error) {
+	hooks, err := scope(r, doer)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, h := range *hooks {
+		if h.ID == id {
+			return hooks, h, nil
+		}
+	}
+	return nil, nil, errNotFound()
+}
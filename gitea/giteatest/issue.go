@@ -0,0 +1,908 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package giteatest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+type issue struct {
+	gitea.Issue
+	labels    []int64
+	milestone int64
+	// pull is set for pull requests, which share the indexes with issues
+	pull *pull
+}
+
+type comment struct {
+	gitea.Comment
+	issue int64
+}
+
+// SeedIssue creates an issue posted by the given user.
+// It panics if the repository or user does not exist or the options are invalid.
+func (s *Server) SeedIssue(owner, repoName, poster string, opt gitea.CreateIssueOption) *gitea.Issue {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rp := s.seededRepo(owner, repoName)
+	is, err := s.createIssue(rp, s.users[strings.ToLower(poster)], opt, nil)
+	must(err)
+	return rp.renderIssue(is)
+}
+
+// SeedLabel creates a label of a repository, it panics if the repository does not exist
+func (s *Server) SeedLabel(owner, repoName string, opt gitea.CreateLabelOption) *gitea.Label {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	l, err := s.createLabel(s.seededRepo(owner, repoName), opt)
+	must(err)
+	cp := *l
+	return &cp
+}
+
+// SeedMilestone creates a milestone of a repository, it panics if the repository does not exist
+func (s *Server) SeedMilestone(owner, repoName string, opt gitea.CreateMilestoneOption) *gitea.Milestone {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rp := s.seededRepo(owner, repoName)
+	m, err := s.createMilestone(rp, opt)
+	must(err)
+	return rp.renderMilestone(m)
+}
+
+// SeedComment creates a comment on an issue or pull request.
+// It panics if the issue or user does not exist.
+func (s *Server) SeedComment(owner, repoName string, index int64, poster, body string) *gitea.Comment {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rp := s.seededRepo(owner, repoName)
+	is := rp.issue(index)
+	if is == nil {
+		must(fmt.Errorf("issue %s/%s#%d does not exist", owner, repoName, index))
+	}
+	c, err := s.createComment(rp, is, s.users[strings.ToLower(poster)], body)
+	must(err)
+	return rp.renderComment(c)
+}
+
+func (rp *repo) issue(index int64) *issue {
+	for _, is := range rp.issues {
+		if is.Index == index {
+			return is
+		}
+	}
+	return nil
+}
+
+func (rp *repo) label(id int64) *gitea.Label {
+	for _, l := range rp.labels {
+		if l.ID == id {
+			return l
+		}
+	}
+	return nil
+}
+
+func (rp *repo) milestoneByID(id int64) *gitea.Milestone {
+	for _, m := range rp.milestones {
+		if m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+// milestone returns a milestone by its id or name
+func (rp *repo) milestone(idOrName string) *gitea.Milestone {
+	if id, err := strconv.ParseInt(idOrName, 10, 64); err == nil {
+		if m := rp.milestoneByID(id); m != nil {
+			return m
+		}
+	}
+	for _, m := range rp.milestones {
+		if m.Title == idOrName {
+			return m
+		}
+	}
+	return nil
+}
+
+func (rp *repo) comment(id int64) *comment {
+	for _, c := range rp.comments {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *Server) createIssue(rp *repo, poster *user, opt gitea.CreateIssueOption, pr *pull) (*issue, error) {
+	if poster == nil {
+		return nil, errNotFound()
+	}
+	if strings.TrimSpace(opt.Title) == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "title is required")
+	}
+	is := &issue{Issue: gitea.Issue{
+		ID:       s.nextID(),
+		Poster:   poster.render(),
+		Title:    opt.Title,
+		Body:     opt.Body,
+		Ref:      opt.Ref,
+		State:    gitea.StateOpen,
+		Deadline: opt.Deadline,
+		Created:  now(),
+		Updated:  now(),
+	}, pull: pr}
+	if err := rp.setLabels(is, opt.Labels); err != nil {
+		return nil, err
+	}
+	if err := rp.setMilestone(is, opt.Milestone); err != nil {
+		return nil, err
+	}
+	if err := s.setAssignees(is, opt.Assignees); err != nil {
+		return nil, err
+	}
+	if opt.Closed {
+		is.close()
+	}
+	rp.lastIndex++
+	is.Index = rp.lastIndex
+	rp.issues = append(rp.issues, is)
+	return is, nil
+}
+
+func (rp *repo) setLabels(is *issue, ids []int64) error {
+	for _, id := range ids {
+		if rp.label(id) == nil {
+			return errorf(http.StatusUnprocessableEntity, "label %d does not exist", id)
+		}
+	}
+	is.labels = slices.Compact(slices.Sorted(slices.Values(ids)))
+	return nil
+}
+
+func (rp *repo) setMilestone(is *issue, id int64) error {
+	if id != 0 && rp.milestoneByID(id) == nil {
+		return errorf(http.StatusUnprocessableEntity, "milestone %d does not exist", id)
+	}
+	is.milestone = id
+	return nil
+}
+
+func (s *Server) setAssignees(is *issue, names []string) error {
+	assignees := make([]*gitea.User, 0, len(names))
+	for _, name := range names {
+		u := s.users[strings.ToLower(name)]
+		if u == nil {
+			return errorf(http.StatusUnprocessableEntity, "user %s does not exist", name)
+		}
+		assignees = append(assignees, u.render())
+	}
+	is.Assignees = assignees
+	return nil
+}
+
+func (is *issue) close() {
+	closed := now()
+	is.State = gitea.StateClosed
+	is.Closed = &closed
+}
+
+func (is *issue) setState(state gitea.StateType) {
+	switch {
+	case state == gitea.StateClosed && is.State != gitea.StateClosed:
+		is.close()
+	case state == gitea.StateOpen:
+		is.State = gitea.StateOpen
+		is.Closed = nil
+	}
+}
+
+func (rp *repo) renderIssue(is *issue) *gitea.Issue {
+	cp := is.Issue
+	cp.Labels = rp.renderLabels(is.labels)
+	if m := rp.milestoneByID(is.milestone); m != nil {
+		cp.Milestone = rp.renderMilestone(m)
+	}
+	for _, c := range rp.comments {
+		if c.issue == is.Index {
+			cp.Comments++
+		}
+	}
+	kind := "issues"
+	if is.pull != nil {
+		kind = "pulls"
+		cp.PullRequest = &gitea.PullRequestMeta{HasMerged: is.pull.merged, Merged: is.pull.mergedAt}
+	}
+	cp.HTMLURL = fmt.Sprintf("%s/%s/%d", rp.HTMLURL, kind, is.Index)
+	cp.URL = cp.HTMLURL
+	cp.Repository = &gitea.RepositoryMeta{
+		ID:       rp.ID,
+		Name:     rp.Name,
+		Owner:    rp.Owner.UserName,
+		FullName: rp.FullName,
+	}
+	return &cp
+}
+
+func (rp *repo) renderLabels(ids []int64) []*gitea.Label {
+	labels := make([]*gitea.Label, 0, len(ids))
+	for _, id := range ids {
+		if l := rp.label(id); l != nil {
+			cp := *l
+			labels = append(labels, &cp)
+		}
+	}
+	return labels
+}
+
+func (rp *repo) renderMilestone(m *gitea.Milestone) *gitea.Milestone {
+	cp := *m
+	for _, is := range rp.issues {
+		if is.milestone != m.ID {
+			continue
+		}
+		if is.State == gitea.StateOpen {
+			cp.OpenIssues++
+		} else {
+			cp.ClosedIssues++
+		}
+	}
+	return &cp
+}
+
+func (rp *repo) renderComment(c *comment) *gitea.Comment {
+	cp := c.Comment
+	cp.IssueURL = fmt.Sprintf("%s/issues/%d", rp.HTMLURL, c.issue)
+	cp.HTMLURL = fmt.Sprintf("%s#issuecomment-%d", cp.IssueURL, c.ID)
+	if is := rp.issue(c.issue); is != nil && is.pull != nil {
+		cp.PRURL = fmt.Sprintf("%s/pulls/%d", rp.HTMLURL, c.issue)
+	}
+	return &cp
+}
+
+func (s *Server) createLabel(rp *repo, opt gitea.CreateLabelOption) (*gitea.Label, error) {
+	if strings.TrimSpace(opt.Name) == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "name is required")
+	}
+	id := s.nextID()
+	l := &gitea.Label{
+		ID:          id,
+		Name:        opt.Name,
+		Color:       strings.TrimPrefix(opt.Color, "#"),
+		Description: opt.Description,
+		URL:         fmt.Sprintf("%s/labels/%d", rp.HTMLURL, id),
+	}
+	rp.labels = append(rp.labels, l)
+	return l, nil
+}
+
+func (s *Server) createMilestone(rp *repo, opt gitea.CreateMilestoneOption) (*gitea.Milestone, error) {
+	if strings.TrimSpace(opt.Title) == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "title is required")
+	}
+	m := &gitea.Milestone{
+		ID:          s.nextID(),
+		Title:       opt.Title,
+		Description: opt.Description,
+		State:       gitea.StateOpen,
+		Created:     now(),
+		Deadline:    opt.Deadline,
+	}
+	setMilestoneState(m, opt.State)
+	rp.milestones = append(rp.milestones, m)
+	return m, nil
+}
+
+func setMilestoneState(m *gitea.Milestone, state gitea.StateType) {
+	switch {
+	case state == gitea.StateClosed && m.State != gitea.StateClosed:
+		closed := now()
+		m.State = gitea.StateClosed
+		m.Closed = &closed
+	case state == gitea.StateOpen:
+		m.State = gitea.StateOpen
+		m.Closed = nil
+	}
+}
+
+func (s *Server) createComment(rp *repo, is *issue, poster *user, body string) (*comment, error) {
+	if poster == nil {
+		return nil, errNotFound()
+	}
+	if strings.TrimSpace(body) == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "body is required")
+	}
+	c := &comment{Comment: gitea.Comment{
+		ID:      s.nextID(),
+		Poster:  poster.render(),
+		Body:    body,
+		Created: now(),
+		Updated: now(),
+	}, issue: is.Index}
+	rp.comments = append(rp.comments, c)
+	is.Updated = now()
+	return c, nil
+}
+
+func (s *Server) registerIssueRoutes() {
+	// comment routes go first, so "comments" is not taken for an issue index
+	s.handle("GET", "/repos/{owner}/{repo}/issues/comments", s.listRepoComments)
+	s.handle("GET", "/repos/{owner}/{repo}/issues/comments/{id}", s.getComment)
+	s.handle("PATCH", "/repos/{owner}/{repo}/issues/comments/{id}", s.editComment)
+	s.handle("DELETE", "/repos/{owner}/{repo}/issues/comments/{id}", s.deleteComment)
+
+	s.handle("GET", "/repos/{owner}/{repo}/issues", s.listIssues)
+	s.handle("POST", "/repos/{owner}/{repo}/issues", s.createIssueHandler)
+	s.handle("GET", "/repos/{owner}/{repo}/issues/{index}", s.getIssue)
+	s.handle("PATCH", "/repos/{owner}/{repo}/issues/{index}", s.editIssue)
+	s.handle("DELETE", "/repos/{owner}/{repo}/issues/{index}", s.deleteIssue)
+	s.handle("GET", "/repos/{owner}/{repo}/issues/{index}/comments", s.listIssueComments)
+	s.handle("POST", "/repos/{owner}/{repo}/issues/{index}/comments", s.createCommentHandler)
+
+	s.handle("GET", "/repos/{owner}/{repo}/issues/{index}/labels", s.getIssueLabels)
+	s.handle("POST", "/repos/{owner}/{repo}/issues/{index}/labels", s.addIssueLabels)
+	s.handle("PUT", "/repos/{owner}/{repo}/issues/{index}/labels", s.replaceIssueLabels)
+	s.handle("DELETE", "/repos/{owner}/{repo}/issues/{index}/labels", s.clearIssueLabels)
+	s.handle("DELETE", "/repos/{owner}/{repo}/issues/{index}/labels/{id}", s.deleteIssueLabel)
+
+	s.handle("GET", "/repos/{owner}/{repo}/labels", s.listLabels)
+	s.handle("POST", "/repos/{owner}/{repo}/labels", s.createLabelHandler)
+	s.handle("GET", "/repos/{owner}/{repo}/labels/{id}", s.getLabel)
+	s.handle("PATCH", "/repos/{owner}/{repo}/labels/{id}", s.editLabel)
+	s.handle("DELETE", "/repos/{owner}/{repo}/labels/{id}", s.deleteLabel)
+
+	s.handle("GET", "/repos/{owner}/{repo}/milestones", s.listMilestones)
+	s.handle("POST", "/repos/{owner}/{repo}/milestones", s.createMilestoneHandler)
+	s.handle("GET", "/repos/{owner}/{repo}/milestones/{id}", s.getMilestone)
+	s.handle("PATCH", "/repos/{owner}/{repo}/milestones/{id}", s.editMilestone)
+	s.handle("DELETE", "/repos/{owner}/{repo}/milestones/{id}", s.deleteMilestone)
+}
+
+// issueFor returns the repository and issue of the request, writing is allowed
+// to the poster and users who may change the repository
+func (s *Server) issueFor(r *http.Request, doer *user, write bool) (*repo, *issue, error) {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	index, err := pathID(r, "index")
+	if err != nil {
+		return nil, nil, err
+	}
+	is := rp.issue(index)
+	if is == nil {
+		return nil, nil, errNotFound()
+	}
+	if write {
+		if err := requireUser(doer); err != nil {
+			return nil, nil, err
+		}
+		if doer.ID != is.Poster.ID && !s.canWrite(rp, doer) {
+			return nil, nil, errorf(http.StatusForbidden, "user may not change the issue")
+		}
+	}
+	return rp, is, nil
+}
+
+// filterIssues returns the issues matching the query of a list request
+func (rp *repo) filterIssues(r *http.Request) []*issue {
+	query := r.URL.Query()
+	kind := gitea.IssueType(query.Get("type"))
+	state := gitea.StateType(query.Get("state"))
+	if state == "" {
+		state = gitea.StateOpen
+	}
+	var labels, milestones []string
+	if l := query.Get("labels"); l != "" {
+		labels = strings.Split(l, ",")
+	}
+	if m := query.Get("milestones"); m != "" {
+		milestones = strings.Split(m, ",")
+	}
+	if m := query.Get("milestone"); m != "" && m != "0" {
+		milestones = append(milestones, m)
+	}
+	keyword := strings.ToLower(query.Get("q"))
+
+	var result []*issue
+	for _, is := range rp.issues {
+		switch {
+		case kind == gitea.IssueTypeIssue && is.pull != nil:
+		case kind == gitea.IssueTypePull && is.pull == nil:
+		case state != gitea.StateAll && is.State != state:
+		case keyword != "" && !strings.Contains(strings.ToLower(is.Title+" "+is.Body), keyword):
+		case query.Get("created_by") != "" && !strings.EqualFold(is.Poster.UserName, query.Get("created_by")):
+		case query.Get("assigned_by") != "" && !slices.ContainsFunc(is.Assignees, func(u *gitea.User) bool {
+			return strings.EqualFold(u.UserName, query.Get("assigned_by"))
+		}):
+		case !rp.hasLabels(is, labels):
+		case len(milestones) != 0 && !slices.ContainsFunc(milestones, func(m string) bool {
+			ms := rp.milestone(m)
+			return ms != nil && ms.ID == is.milestone
+		}):
+		default:
+			result = append(result, is)
+		}
+	}
+	return result
+}
+
+func (rp *repo) hasLabels(is *issue, names []string) bool {
+	for _, name := range names {
+		if !slices.ContainsFunc(is.labels, func(id int64) bool {
+			l := rp.label(id)
+			return l != nil && strings.EqualFold(l.Name, name)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) listIssues(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	issues := rp.filterIssues(r)
+	result := make([]*gitea.Issue, 0, len(issues))
+	for _, is := range paginate(w, r, issues) {
+		result = append(result, rp.renderIssue(is))
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createIssueHandler(w http.ResponseWriter, r *http.Request, doer *user) error {
+	if err := requireUser(doer); err != nil {
+		return err
+	}
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	var opt gitea.CreateIssueOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	is, err := s.createIssue(rp, doer, opt, nil)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, rp.renderIssue(is))
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, is, err := s.issueFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, rp.renderIssue(is))
+}
+
+func (s *Server) editIssue(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, is, err := s.issueFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.EditIssueOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	if opt.Title != "" {
+		is.Title = opt.Title
+	}
+	if opt.Body != nil {
+		is.Body = *opt.Body
+	}
+	if opt.Ref != nil {
+		is.Ref = *opt.Ref
+	}
+	if opt.Assignees != nil {
+		if err := s.setAssignees(is, opt.Assignees); err != nil {
+			return err
+		}
+	}
+	if opt.Milestone != nil {
+		if err := rp.setMilestone(is, *opt.Milestone); err != nil {
+			return err
+		}
+	}
+	if opt.Deadline != nil {
+		is.Deadline = opt.Deadline
+	}
+	if opt.RemoveDeadline != nil && *opt.RemoveDeadline {
+		is.Deadline = nil
+	}
+	if opt.State != nil {
+		if is.pull != nil && is.pull.merged && *opt.State == gitea.StateOpen {
+			return errorf(http.StatusPreconditionFailed, "cannot reopen a merged pull request")
+		}
+		is.setState(*opt.State)
+	}
+	is.Updated = now()
+	return writeJSON(w, http.StatusCreated, rp.renderIssue(is))
+}
+
+func (s *Server) deleteIssue(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, is, err := s.issueFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	if !s.canWrite(rp, doer) {
+		return errorf(http.StatusForbidden, "user may not delete the issue")
+	}
+	rp.issues = slices.DeleteFunc(rp.issues, func(other *issue) bool { return other == is })
+	rp.comments = slices.DeleteFunc(rp.comments, func(c *comment) bool { return c.issue == is.Index })
+	return writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) renderComments(w http.ResponseWriter, r *http.Request, rp *repo, filter func(*comment) bool) error {
+	var comments []*comment
+	for _, c := range rp.comments {
+		if filter(c) {
+			comments = append(comments, c)
+		}
+	}
+	result := make([]*gitea.Comment, 0, len(comments))
+	for _, c := range paginate(w, r, comments) {
+		result = append(result, rp.renderComment(c))
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) listRepoComments(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return s.renderComments(w, r, rp, func(*comment) bool { return true })
+}
+
+func (s *Server) listIssueComments(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, is, err := s.issueFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return s.renderComments(w, r, rp, func(c *comment) bool { return c.issue == is.Index })
+}
+
+func (s *Server) createCommentHandler(w http.ResponseWriter, r *http.Request, doer *user) error {
+	if err := requireUser(doer); err != nil {
+		return err
+	}
+	rp, is, err := s.issueFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	var opt gitea.CreateIssueCommentOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	c, err := s.createComment(rp, is, doer, opt.Body)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, rp.renderComment(c))
+}
+
+// commentFor returns the repository and comment of the request, writing is allowed
+// to the poster and users who may change the repository
+func (s *Server) commentFor(r *http.Request, doer *user, write bool) (*repo, *comment, error) {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, nil, err
+	}
+	c := rp.comment(id)
+	if c == nil {
+		return nil, nil, errNotFound()
+	}
+	if write {
+		if err := requireUser(doer); err != nil {
+			return nil, nil, err
+		}
+		if doer.ID != c.Poster.ID && !s.canWrite(rp, doer) {
+			return nil, nil, errorf(http.StatusForbidden, "user may not change the comment")
+		}
+	}
+	return rp, c, nil
+}
+
+func (s *Server) getComment(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, c, err := s.commentFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, rp.renderComment(c))
+}
+
+func (s *Server) editComment(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, c, err := s.commentFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.EditIssueCommentOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	if strings.TrimSpace(opt.Body) == "" {
+		return errorf(http.StatusUnprocessableEntity, "body is required")
+	}
+	c.Body = opt.Body
+	c.Updated = now()
+	return writeJSON(w, http.StatusOK, rp.renderComment(c))
+}
+
+func (s *Server) deleteComment(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, c, err := s.commentFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	rp.comments = slices.DeleteFunc(rp.comments, func(other *comment) bool { return other == c })
+	return writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) getIssueLabels(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, is, err := s.issueFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, rp.renderLabels(is.labels))
+}
+
+// changeIssueLabels applies change to the labels of an issue and answers with the new labels
+func (s *Server) changeIssueLabels(w http.ResponseWriter, r *http.Request, doer *user, change func(rp *repo, is *issue, ids []int64) error) error {
+	rp, is, err := s.issueFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	if !s.canWrite(rp, doer) {
+		if err := requireUser(doer); err != nil {
+			return err
+		}
+		return errorf(http.StatusForbidden, "user may not change the labels")
+	}
+	var opt gitea.IssueLabelsOption
+	if r.Method == "POST" || r.Method == "PUT" {
+		if err := decode(r, &opt); err != nil {
+			return err
+		}
+	}
+	if err := change(rp, is, opt.Labels); err != nil {
+		return err
+	}
+	is.Updated = now()
+	if r.Method == "DELETE" {
+		return writeJSON(w, http.StatusNoContent, nil)
+	}
+	return writeJSON(w, http.StatusOK, rp.renderLabels(is.labels))
+}
+
+func (s *Server) addIssueLabels(w http.ResponseWriter, r *http.Request, doer *user) error {
+	return s.changeIssueLabels(w, r, doer, func(rp *repo, is *issue, ids []int64) error {
+		return rp.setLabels(is, append(slices.Clone(is.labels), ids...))
+	})
+}
+
+func (s *Server) replaceIssueLabels(w http.ResponseWriter, r *http.Request, doer *user) error {
+	return s.changeIssueLabels(w, r, doer, func(rp *repo, is *issue, ids []int64) error {
+		return rp.setLabels(is, ids)
+	})
+}
+
+func (s *Server) clearIssueLabels(w http.ResponseWriter, r *http.Request, doer *user) error {
+	return s.changeIssueLabels(w, r, doer, func(rp *repo, is *issue, _ []int64) error {
+		return rp.setLabels(is, nil)
+	})
+}
+
+func (s *Server) deleteIssueLabel(w http.ResponseWriter, r *http.Request, doer *user) error {
+	return s.changeIssueLabels(w, r, doer, func(rp *repo, is *issue, _ []int64) error {
+		id, err := pathID(r, "id")
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(is.labels, id) {
+			return errNotFound()
+		}
+		return rp.setLabels(is, slices.DeleteFunc(slices.Clone(is.labels), func(other int64) bool { return other == id }))
+	})
+}
+
+func (s *Server) listLabels(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	result := make([]*gitea.Label, 0, len(rp.labels))
+	for _, l := range paginate(w, r, rp.labels) {
+		cp := *l
+		result = append(result, &cp)
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createLabelHandler(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.CreateLabelOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	l, err := s.createLabel(rp, opt)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, l)
+}
+
+func (s *Server) labelFor(r *http.Request, doer *user, write bool) (*repo, *gitea.Label, error) {
+	rp, err := s.repoFor(r, doer, write)
+	if err != nil {
+		return nil, nil, err
+	}
+	id, err := pathID(r, "id")
+	if err != nil {
+		return nil, nil, err
+	}
+	l := rp.label(id)
+	if l == nil {
+		return nil, nil, errNotFound()
+	}
+	return rp, l, nil
+}
+
+func (s *Server) getLabel(w http.ResponseWriter, r *http.Request, doer *user) error {
+	_, l, err := s.labelFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, l)
+}
+
+func (s *Server) editLabel(w http.ResponseWriter, r *http.Request, doer *user) error {
+	_, l, err := s.labelFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.EditLabelOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	if opt.Name != nil {
+		l.Name = *opt.Name
+	}
+	if opt.Color != nil {
+		l.Color = strings.TrimPrefix(*opt.Color, "#")
+	}
+	if opt.Description != nil {
+		l.Description = *opt.Description
+	}
+	return writeJSON(w, http.StatusOK, l)
+}
+
+func (s *Server) deleteLabel(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, l, err := s.labelFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	rp.labels = slices.DeleteFunc(rp.labels, func(other *gitea.Label) bool { return other == l })
+	for _, is := range rp.issues {
+		is.labels = slices.DeleteFunc(is.labels, func(id int64) bool { return id == l.ID })
+	}
+	return writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) listMilestones(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	state := gitea.StateType(r.URL.Query().Get("state"))
+	if state == "" {
+		state = gitea.StateOpen
+	}
+	name := r.URL.Query().Get("name")
+	var milestones []*gitea.Milestone
+	for _, m := range rp.milestones {
+		if (state == gitea.StateAll || m.State == state) && (name == "" || m.Title == name) {
+			milestones = append(milestones, m)
+		}
+	}
+	result := make([]*gitea.Milestone, 0, len(milestones))
+	for _, m := range paginate(w, r, milestones) {
+		result = append(result, rp.renderMilestone(m))
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createMilestoneHandler(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.CreateMilestoneOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	m, err := s.createMilestone(rp, opt)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, rp.renderMilestone(m))
+}
+
+func (s *Server) milestoneFor(r *http.Request, doer *user, write bool) (*repo, *gitea.Milestone, error) {
+	rp, err := s.repoFor(r, doer, write)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := rp.milestone(r.PathValue("id"))
+	if m == nil {
+		return nil, nil, errNotFound()
+	}
+	return rp, m, nil
+}
+
+func (s *Server) getMilestone(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, m, err := s.milestoneFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, rp.renderMilestone(m))
+}
+
+func (s *Server) editMilestone(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, m, err := s.milestoneFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.EditMilestoneOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	if opt.Title != "" {
+		m.Title = opt.Title
+	}
+	if opt.Description != nil {
+		m.Description = *opt.Description
+	}
+	if opt.State != nil {
+		setMilestoneState(m, *opt.State)
+	}
+	if opt.Deadline != nil {
+		m.Deadline = opt.Deadline
+	}
+	updated := now()
+	m.Updated = &updated
+	return writeJSON(w, http.StatusOK, rp.renderMilestone(m))
+}
+
+func (s *Server) deleteMilestone(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, m, err := s.milestoneFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	rp.milestones = slices.DeleteFunc(rp.milestones, func(other *gitea.Milestone) bool { return other == m })
+	for _, is := range rp.issues {
+		if is.milestone == m.ID {
+			is.milestone = 0
+		}
+	}
+	return writeJSON(w, http.StatusNoContent, nil)
+}
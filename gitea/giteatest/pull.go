@@ -0,0 +1,321 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package giteatest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// pull holds the pull request specific state of an issue,
+// only pull requests between branches of the same repository are supported
+type pull struct {
+	head, base          string
+	headSHA             string
+	merged              bool
+	mergedAt            *time.Time
+	mergedBy            *gitea.User
+	mergeCommit         string
+	allowMaintainerEdit bool
+}
+
+// SeedPullRequest creates a pull request posted by the given user, head and base have to be
+// branches of the repository. It panics if the repository, branches or user do not exist.
+func (s *Server) SeedPullRequest(owner, repoName, poster string, opt gitea.CreatePullRequestOption) *gitea.PullRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rp := s.seededRepo(owner, repoName)
+	is, err := s.createPull(rp, s.users[strings.ToLower(poster)], opt)
+	must(err)
+	return rp.renderPull(is)
+}
+
+func (s *Server) createPull(rp *repo, poster *user, opt gitea.CreatePullRequestOption) (*issue, error) {
+	// head may be given as "owner:branch"
+	if owner, branch, ok := strings.Cut(opt.Head, ":"); ok {
+		if !strings.EqualFold(owner, rp.Owner.UserName) {
+			return nil, errorf(http.StatusUnprocessableEntity, "pull requests from forks are not supported")
+		}
+		opt.Head = branch
+	}
+	if opt.Head == "" || opt.Base == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "head and base are required")
+	}
+	if opt.Head == opt.Base {
+		return nil, errorf(http.StatusUnprocessableEntity, "head and base must differ")
+	}
+	if rp.branch(opt.Head) == nil || rp.branch(opt.Base) == nil {
+		return nil, errorf(http.StatusNotFound, "branch does not exist")
+	}
+	for _, is := range rp.issues {
+		if is.pull != nil && is.State == gitea.StateOpen && is.pull.head == opt.Head && is.pull.base == opt.Base {
+			return nil, errorf(http.StatusConflict, "pull request already exists for these targets")
+		}
+	}
+
+	assignees := opt.Assignees
+	if opt.Assignee != "" {
+		assignees = append([]string{opt.Assignee}, assignees...)
+	}
+	return s.createIssue(rp, poster, gitea.CreateIssueOption{
+		Title:     opt.Title,
+		Body:      opt.Body,
+		Assignees: assignees,
+		Deadline:  opt.Deadline,
+		Milestone: opt.Milestone,
+		Labels:    opt.Labels,
+	}, &pull{head: opt.Head, base: opt.Base, allowMaintainerEdit: true})
+}
+
+func (rp *repo) renderPull(is *issue) *gitea.PullRequest {
+	rendered := rp.renderIssue(is)
+	pr := is.pull
+	created, updated := is.Created, is.Updated
+	result := &gitea.PullRequest{
+		ID:                  is.ID,
+		URL:                 rendered.URL,
+		Index:               is.Index,
+		Poster:              rendered.Poster,
+		Title:               is.Title,
+		Body:                is.Body,
+		Labels:              rendered.Labels,
+		Milestone:           rendered.Milestone,
+		Assignees:           rendered.Assignees,
+		State:               is.State,
+		IsLocked:            is.IsLocked,
+		Comments:            rendered.Comments,
+		HTMLURL:             rendered.HTMLURL,
+		DiffURL:             rendered.HTMLURL + ".diff",
+		PatchURL:            rendered.HTMLURL + ".patch",
+		HasMerged:           pr.merged,
+		Merged:              pr.mergedAt,
+		MergedBy:            pr.mergedBy,
+		AllowMaintainerEdit: pr.allowMaintainerEdit,
+		Base:                rp.branchInfo(pr.base, ""),
+		Head:                rp.branchInfo(pr.head, pr.headSHA),
+		Deadline:            is.Deadline,
+		Created:             &created,
+		Updated:             &updated,
+		Closed:              is.Closed,
+	}
+	if len(rendered.Assignees) != 0 {
+		result.Assignee = rendered.Assignees[0]
+	}
+	if pr.merged {
+		result.MergedCommitID = &pr.mergeCommit
+	}
+	result.Mergeable = is.State == gitea.StateOpen && rp.branch(pr.head) != nil && rp.branch(pr.base) != nil
+	result.MergeBase = result.Base.Sha
+	return result
+}
+
+// branchInfo describes a branch of a pull request, sha is used if the branch was deleted
+func (rp *repo) branchInfo(branch, sha string) *gitea.PRBranchInfo {
+	if b := rp.branch(branch); b != nil {
+		sha = b.Commit.ID
+	}
+	return &gitea.PRBranchInfo{
+		Name:       branch,
+		Ref:        branch,
+		Sha:        sha,
+		RepoID:     rp.ID,
+		Repository: rp.render(nil),
+	}
+}
+
+func (s *Server) registerPullRoutes() {
+	s.handle("GET", "/repos/{owner}/{repo}/pulls", s.listPulls)
+	s.handle("POST", "/repos/{owner}/{repo}/pulls", s.createPullHandler)
+	s.handle("GET", "/repos/{owner}/{repo}/pulls/{index}", s.getPull)
+	s.handle("PATCH", "/repos/{owner}/{repo}/pulls/{index}", s.editPull)
+	s.handle("GET", "/repos/{owner}/{repo}/pulls/{index}/merge", s.isPullMerged)
+	s.handle("POST", "/repos/{owner}/{repo}/pulls/{index}/merge", s.mergePull)
+}
+
+// pullFor returns the repository and pull request of the request
+func (s *Server) pullFor(r *http.Request, doer *user, write bool) (*repo, *issue, error) {
+	rp, is, err := s.issueFor(r, doer, write)
+	if err != nil {
+		return nil, nil, err
+	}
+	if is.pull == nil {
+		return nil, nil, errNotFound()
+	}
+	return rp, is, nil
+}
+
+func (s *Server) listPulls(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	state := gitea.StateType(r.URL.Query().Get("state"))
+	if state == "" {
+		state = gitea.StateOpen
+	}
+	milestone, _ := strconv.ParseInt(r.URL.Query().Get("milestone"), 10, 64)
+	var pulls []*issue
+	for _, is := range rp.issues {
+		if is.pull != nil && (state == gitea.StateAll || is.State == state) && (milestone == 0 || is.milestone == milestone) {
+			pulls = append(pulls, is)
+		}
+	}
+	result := make([]*gitea.PullRequest, 0, len(pulls))
+	for _, is := range paginate(w, r, pulls) {
+		result = append(result, rp.renderPull(is))
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createPullHandler(w http.ResponseWriter, r *http.Request, doer *user) error {
+	if err := requireUser(doer); err != nil {
+		return err
+	}
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	var opt gitea.CreatePullRequestOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	is, err := s.createPull(rp, doer, opt)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, rp.renderPull(is))
+}
+
+func (s *Server) getPull(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, is, err := s.pullFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, rp.renderPull(is))
+}
+
+func (s *Server) editPull(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, is, err := s.pullFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.EditPullRequestOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	if opt.Title != "" {
+		is.Title = opt.Title
+	}
+	if opt.Body != "" {
+		is.Body = opt.Body
+	}
+	if opt.Base != "" && opt.Base != is.pull.base {
+		if rp.branch(opt.Base) == nil {
+			return errorf(http.StatusNotFound, "branch %s does not exist", opt.Base)
+		}
+		is.pull.base = opt.Base
+	}
+	if opt.Assignee != "" || opt.Assignees != nil {
+		assignees := opt.Assignees
+		if opt.Assignee != "" {
+			assignees = append([]string{opt.Assignee}, assignees...)
+		}
+		if err := s.setAssignees(is, assignees); err != nil {
+			return err
+		}
+	}
+	if opt.Milestone != 0 {
+		if err := rp.setMilestone(is, opt.Milestone); err != nil {
+			return err
+		}
+	}
+	if opt.Labels != nil {
+		if err := rp.setLabels(is, opt.Labels); err != nil {
+			return err
+		}
+	}
+	if opt.Deadline != nil {
+		is.Deadline = opt.Deadline
+	}
+	if opt.RemoveDeadline != nil && *opt.RemoveDeadline {
+		is.Deadline = nil
+	}
+	if opt.AllowMaintainerEdit != nil {
+		is.pull.allowMaintainerEdit = *opt.AllowMaintainerEdit
+	}
+	if opt.State != nil {
+		if is.pull.merged && *opt.State == gitea.StateOpen {
+			return errorf(http.StatusPreconditionFailed, "cannot reopen a merged pull request")
+		}
+		is.setState(*opt.State)
+	}
+	is.Updated = now()
+	return writeJSON(w, http.StatusCreated, rp.renderPull(is))
+}
+
+func (s *Server) isPullMerged(w http.ResponseWriter, r *http.Request, doer *user) error {
+	_, is, err := s.pullFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	if !is.pull.merged {
+		return errNotFound()
+	}
+	return writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) mergePull(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, is, err := s.pullFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	if !s.canWrite(rp, doer) {
+		if err := requireUser(doer); err != nil {
+			return err
+		}
+		return errorf(http.StatusForbidden, "user may not merge the pull request")
+	}
+	var opt gitea.MergePullRequestOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	pr := is.pull
+	switch {
+	case pr.merged:
+		return errorf(http.StatusMethodNotAllowed, "The PR is already merged")
+	case is.State != gitea.StateOpen:
+		return errorf(http.StatusMethodNotAllowed, "The PR is closed")
+	case rp.branch(pr.head) == nil || rp.branch(pr.base) == nil:
+		return errorf(http.StatusMethodNotAllowed, "Please try again later")
+	case opt.HeadCommitId != "" && opt.HeadCommitId != rp.branch(pr.head).Commit.ID:
+		return errorf(http.StatusConflict, "head out of date")
+	}
+
+	message := opt.Title
+	if message == "" {
+		message = fmt.Sprintf("Merge pull request '%s' (#%d) from %s into %s", is.Title, is.Index, pr.head, pr.base)
+	}
+	mergedAt := now()
+	pr.headSHA = rp.branch(pr.head).Commit.ID
+	pr.mergeCommit = rp.pushCommit(pr.base, message)
+	pr.merged = true
+	pr.mergedAt = &mergedAt
+	pr.mergedBy = doer.render()
+	is.close()
+	if opt.DeleteBranchAfterMerge && pr.head != rp.DefaultBranch {
+		for i, b := range rp.branches {
+			if b.Name == pr.head {
+				rp.branches = append(rp.branches[:i], rp.branches[i+1:]...)
+				break
+			}
+		}
+	}
+	return writeJSON(w, http.StatusOK, nil)
+}
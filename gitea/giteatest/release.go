@@ -0,0 +1,227 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package giteatest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// SeedRelease creates a release published by the given user.
+// It panics if the repository or user does not exist or the tag is taken.
+func (s *Server) SeedRelease(owner, repoName, publisher string, opt gitea.CreateReleaseOption) *gitea.Release {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rel, err := s.createRelease(s.seededRepo(owner, repoName), s.users[strings.ToLower(publisher)], opt)
+	must(err)
+	return renderRelease(rel)
+}
+
+func (s *Server) createRelease(rp *repo, publisher *user, opt gitea.CreateReleaseOption) (*gitea.Release, error) {
+	if publisher == nil {
+		return nil, errNotFound()
+	}
+	if strings.TrimSpace(opt.TagName) == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "tag_name is required")
+	}
+	if rp.releaseByTag(opt.TagName) != nil {
+		return nil, errorf(http.StatusConflict, "release with tag %s already exists", opt.TagName)
+	}
+	if opt.Target == "" {
+		opt.Target = rp.DefaultBranch
+	}
+	created := now()
+	id := s.nextID()
+	rel := &gitea.Release{
+		ID:           id,
+		TagName:      opt.TagName,
+		Target:       opt.Target,
+		Title:        opt.Title,
+		Note:         opt.Note,
+		URL:          fmt.Sprintf("%s/releases/%d", rp.HTMLURL, id),
+		HTMLURL:      fmt.Sprintf("%s/releases/tag/%s", rp.HTMLURL, opt.TagName),
+		TarURL:       fmt.Sprintf("%s/archive/%s.tar.gz", rp.HTMLURL, opt.TagName),
+		ZipURL:       fmt.Sprintf("%s/archive/%s.zip", rp.HTMLURL, opt.TagName),
+		IsDraft:      opt.IsDraft,
+		IsPrerelease: opt.IsPrerelease,
+		CreatedAt:    created,
+		PublishedAt:  created,
+		Publisher:    publisher.render(),
+	}
+	rp.releases = append(rp.releases, rel)
+	return rel, nil
+}
+
+func (rp *repo) releaseByTag(tag string) *gitea.Release {
+	for _, rel := range rp.releases {
+		if rel.TagName == tag {
+			return rel
+		}
+	}
+	return nil
+}
+
+func renderRelease(rel *gitea.Release) *gitea.Release {
+	cp := *rel
+	cp.Attachments = []*gitea.Attachment{}
+	return &cp
+}
+
+func (s *Server) registerReleaseRoutes() {
+	s.handle("GET", "/repos/{owner}/{repo}/releases", s.listReleases)
+	s.handle("POST", "/repos/{owner}/{repo}/releases", s.createReleaseHandler)
+	s.handle("GET", "/repos/{owner}/{repo}/releases/latest", s.getLatestRelease)
+	s.handle("GET", "/repos/{owner}/{repo}/releases/tags/{tag}", s.getRelease)
+	s.handle("DELETE", "/repos/{owner}/{repo}/releases/tags/{tag}", s.deleteRelease)
+	s.handle("GET", "/repos/{owner}/{repo}/releases/{id}", s.getRelease)
+	s.handle("PATCH", "/repos/{owner}/{repo}/releases/{id}", s.editRelease)
+	s.handle("DELETE", "/repos/{owner}/{repo}/releases/{id}", s.deleteRelease)
+}
+
+// releases returns the releases visible to doer, newest first, drafts are only visible to writers
+func (s *Server) releases(rp *repo, doer *user) []*gitea.Release {
+	writer := s.canWrite(rp, doer)
+	var result []*gitea.Release
+	for _, rel := range slices.Backward(rp.releases) {
+		if !rel.IsDraft || writer {
+			result = append(result, rel)
+		}
+	}
+	return result
+}
+
+func (s *Server) listReleases(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	query := r.URL.Query()
+	var releases []*gitea.Release
+	for _, rel := range s.releases(rp, doer) {
+		if draft, err := strconv.ParseBool(query.Get("draft")); err == nil && rel.IsDraft != draft {
+			continue
+		}
+		if pre, err := strconv.ParseBool(query.Get("pre-release")); err == nil && rel.IsPrerelease != pre {
+			continue
+		}
+		releases = append(releases, rel)
+	}
+	result := make([]*gitea.Release, 0, len(releases))
+	for _, rel := range paginate(w, r, releases) {
+		result = append(result, renderRelease(rel))
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createReleaseHandler(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.CreateReleaseOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	rel, err := s.createRelease(rp, doer, opt)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, renderRelease(rel))
+}
+
+func (s *Server) getLatestRelease(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	for _, rel := range s.releases(rp, doer) {
+		if !rel.IsDraft && !rel.IsPrerelease {
+			return writeJSON(w, http.StatusOK, renderRelease(rel))
+		}
+	}
+	return errNotFound()
+}
+
+// releaseFor returns the repository and the release of the request, selected by id or tag
+func (s *Server) releaseFor(r *http.Request, doer *user, write bool) (*repo, *gitea.Release, error) {
+	rp, err := s.repoFor(r, doer, write)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rel *gitea.Release
+	if tag := r.PathValue("tag"); tag != "" {
+		rel = rp.releaseByTag(tag)
+	} else {
+		id, err := pathID(r, "id")
+		if err != nil {
+			return nil, nil, err
+		}
+		if i := slices.IndexFunc(rp.releases, func(rel *gitea.Release) bool { return rel.ID == id }); i >= 0 {
+			rel = rp.releases[i]
+		}
+	}
+	if rel == nil || (rel.IsDraft && !s.canWrite(rp, doer)) {
+		return nil, nil, errNotFound()
+	}
+	return rp, rel, nil
+}
+
+func (s *Server) getRelease(w http.ResponseWriter, r *http.Request, doer *user) error {
+	_, rel, err := s.releaseFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, renderRelease(rel))
+}
+
+func (s *Server) editRelease(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, rel, err := s.releaseFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.EditReleaseOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	if opt.TagName != "" && opt.TagName != rel.TagName {
+		if rp.releaseByTag(opt.TagName) != nil {
+			return errorf(http.StatusConflict, "tag %s is already used by a release", opt.TagName)
+		}
+		rel.TagName = opt.TagName
+		rel.HTMLURL = fmt.Sprintf("%s/releases/tag/%s", rp.HTMLURL, opt.TagName)
+		rel.TarURL = fmt.Sprintf("%s/archive/%s.tar.gz", rp.HTMLURL, opt.TagName)
+		rel.ZipURL = fmt.Sprintf("%s/archive/%s.zip", rp.HTMLURL, opt.TagName)
+	}
+	if opt.Target != "" {
+		rel.Target = opt.Target
+	}
+	if opt.Title != "" {
+		rel.Title = opt.Title
+	}
+	if opt.Note != "" {
+		rel.Note = opt.Note
+	}
+	if opt.IsDraft != nil {
+		rel.IsDraft = *opt.IsDraft
+	}
+	if opt.IsPrerelease != nil {
+		rel.IsPrerelease = *opt.IsPrerelease
+	}
+	return writeJSON(w, http.StatusOK, renderRelease(rel))
+}
+
+func (s *Server) deleteRelease(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, rel, err := s.releaseFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	rp.releases = slices.DeleteFunc(rp.releases, func(other *gitea.Release) bool { return other == rel })
+	return writeJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,478 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package giteatest
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+type repo struct {
+	gitea.Repository
+	branches   []*gitea.Branch
+	issues     []*issue
+	labels     []*gitea.Label
+	milestones []*gitea.Milestone
+	comments   []*comment
+	releases   []*gitea.Release
+	hooks      []*gitea.Hook
+	secrets    map[string]*secret
+	vars       map[string]*gitea.Variable
+	// lastIndex is the last index used by an issue or pull request
+	lastIndex int64
+}
+
+// SeedRepo creates a repository for a user or an organization. The default branch
+// is only created if AutoInit is set, like Gitea does.
+// It panics if the owner does not exist or the repository already exists.
+func (s *Server) SeedRepo(owner string, opt gitea.CreateRepoOption) *gitea.Repository {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rp, err := s.createRepo(s.owner(owner), opt)
+	must(err)
+	return rp.render(nil)
+}
+
+// SeedBranch creates a branch pointing to the head of the default branch,
+// the default branch is created if the repository is empty.
+// It panics if the repository does not exist or the branch already exists.
+func (s *Server) SeedBranch(owner, repoName, branch string) *gitea.Branch {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	rp := s.seededRepo(owner, repoName)
+	if rp.Empty {
+		rp.pushCommit(rp.DefaultBranch, "Initial commit")
+	}
+	b, err := rp.createBranch(gitea.CreateBranchOption{BranchName: branch})
+	must(err)
+	return renderBranch(b)
+}
+
+// seededRepo returns a repository for a seeding helper, it panics if it does not exist
+func (s *Server) seededRepo(owner, name string) *repo {
+	rp := s.repos[repoKey(owner, name)]
+	if rp == nil {
+		must(fmt.Errorf("repository %s/%s does not exist", owner, name))
+	}
+	return rp
+}
+
+func repoKey(owner, name string) string {
+	return strings.ToLower(owner + "/" + name)
+}
+
+// fakeSHA returns a stable sha1 hash of the given parts
+func fakeSHA(parts ...any) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprint(parts...))))
+}
+
+func (s *Server) createRepo(owner *gitea.User, opt gitea.CreateRepoOption) (*repo, error) {
+	if owner == nil {
+		return nil, errNotFound()
+	}
+	if err := checkName(opt.Name); err != nil {
+		return nil, err
+	}
+	key := repoKey(owner.UserName, opt.Name)
+	if s.repos[key] != nil {
+		return nil, errorf(http.StatusConflict, "The repository with the same name already exists.")
+	}
+	if opt.DefaultBranch == "" {
+		opt.DefaultBranch = "main"
+	}
+	fullName := owner.UserName + "/" + opt.Name
+	created := now()
+	rp := &repo{Repository: gitea.Repository{
+		ID:              s.nextID(),
+		Owner:           owner,
+		Name:            opt.Name,
+		FullName:        fullName,
+		Description:     opt.Description,
+		Empty:           true,
+		Private:         opt.Private,
+		Template:        opt.Template,
+		HTMLURL:         s.URL + "/" + fullName,
+		CloneURL:        s.URL + "/" + fullName + ".git",
+		DefaultBranch:   opt.DefaultBranch,
+		Created:         created,
+		Updated:         created,
+		HasIssues:       true,
+		HasWiki:         true,
+		HasPullRequests: true,
+		HasProjects:     true,
+		HasReleases:     true,
+		HasPackages:     true,
+		HasActions:      true,
+		AllowMerge:      true,
+		AllowRebase:     true,
+		AllowSquash:     true,
+	}}
+	if opt.AutoInit {
+		rp.pushCommit(opt.DefaultBranch, "Initial commit")
+	}
+	s.repos[key] = rp
+	return rp, nil
+}
+
+// canWrite reports whether a user may change the repository
+func (s *Server) canWrite(rp *repo, doer *user) bool {
+	if doer == nil {
+		return false
+	}
+	if doer.IsAdmin || doer.ID == rp.Owner.ID {
+		return true
+	}
+	o := s.orgs[strings.ToLower(rp.Owner.UserName)]
+	return o != nil && o.members[strings.ToLower(doer.UserName)]
+}
+
+func (s *Server) canRead(rp *repo, doer *user) bool {
+	return !rp.Private || s.canWrite(rp, doer)
+}
+
+// repoFor returns the repository of the request, private repositories are hidden from users
+// without access and writing is only allowed to users who may change the repository
+func (s *Server) repoFor(r *http.Request, doer *user, write bool) (*repo, error) {
+	rp := s.repos[repoKey(r.PathValue("owner"), r.PathValue("repo"))]
+	if rp == nil || !s.canRead(rp, doer) {
+		return nil, errNotFound()
+	}
+	if write && !s.canWrite(rp, doer) {
+		if err := requireUser(doer); err != nil {
+			return nil, err
+		}
+		return nil, errorf(http.StatusForbidden, "user has no write access to the repository")
+	}
+	return rp, nil
+}
+
+func (rp *repo) render(doer *user) *gitea.Repository {
+	cp := rp.Repository
+	owner := *rp.Owner
+	cp.Owner = &owner
+	cp.Size = len(rp.branches)
+	for _, is := range rp.issues {
+		if is.State != gitea.StateOpen {
+			continue
+		}
+		if is.pull != nil {
+			cp.OpenPulls++
+		} else {
+			cp.OpenIssues++
+		}
+	}
+	cp.Releases = len(rp.releases)
+	if doer != nil {
+		admin := doer.IsAdmin || doer.ID == rp.Owner.ID
+		cp.Permissions = &gitea.Permission{Admin: admin, Push: true, Pull: true}
+	}
+	return &cp
+}
+
+// renderRepos renders the repositories visible to doer, ordered by id
+func (s *Server) renderRepos(w http.ResponseWriter, r *http.Request, doer *user, filter func(*repo) bool) error {
+	var visible []*repo
+	for _, rp := range sortedValues(s.repos, func(rp *repo) int64 { return rp.ID }) {
+		if s.canRead(rp, doer) && filter(rp) {
+			visible = append(visible, rp)
+		}
+	}
+	result := make([]*gitea.Repository, 0, len(visible))
+	for _, rp := range paginate(w, r, visible) {
+		var perm *user
+		if s.canWrite(rp, doer) {
+			perm = doer
+		}
+		result = append(result, rp.render(perm))
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (rp *repo) branch(name string) *gitea.Branch {
+	for _, b := range rp.branches {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// pushCommit adds a commit to a branch, creating the branch if needed, and returns its sha
+func (rp *repo) pushCommit(branch, message string) string {
+	b := rp.branch(branch)
+	if b == nil {
+		b = &gitea.Branch{Name: branch, Commit: &gitea.PayloadCommit{}}
+		rp.branches = append(rp.branches, b)
+	}
+	sha := fakeSHA(rp.ID, branch, b.Commit.ID, message)
+	b.Commit = &gitea.PayloadCommit{
+		ID:        sha,
+		Message:   message,
+		URL:       rp.HTMLURL + "/commit/" + sha,
+		Timestamp: now(),
+	}
+	rp.Empty = false
+	rp.Updated = now()
+	return sha
+}
+
+func (rp *repo) createBranch(opt gitea.CreateBranchOption) (*gitea.Branch, error) {
+	if opt.BranchName == "" {
+		return nil, errorf(http.StatusUnprocessableEntity, "new_branch_name is required")
+	}
+	if rp.branch(opt.BranchName) != nil {
+		return nil, errorf(http.StatusConflict, "The branch already exists.")
+	}
+	if opt.OldBranchName == "" {
+		opt.OldBranchName = rp.DefaultBranch
+	}
+	old := rp.branch(opt.OldBranchName)
+	if old == nil {
+		return nil, errorf(http.StatusNotFound, "The old branch does not exist")
+	}
+	commit := *old.Commit
+	b := &gitea.Branch{Name: opt.BranchName, Commit: &commit}
+	rp.branches = append(rp.branches, b)
+	return b, nil
+}
+
+func renderBranch(b *gitea.Branch) *gitea.Branch {
+	cp := *b
+	commit := *b.Commit
+	cp.Commit = &commit
+	cp.UserCanPush = true
+	cp.UserCanMerge = true
+	return &cp
+}
+
+func (s *Server) registerRepoRoutes() {
+	s.handle("GET", "/user/repos", s.listMyRepos)
+	s.handle("POST", "/user/repos", s.createMyRepo)
+	s.handle("GET", "/users/{username}/repos", s.listUserRepos)
+	s.handle("GET", "/orgs/{org}/repos", s.listOrgRepos)
+	s.handle("POST", "/org/{org}/repos", s.createOrgRepo)
+	s.handle("POST", "/orgs/{org}/repos", s.createOrgRepo)
+	s.handle("GET", "/repositories/{id}", s.getRepoByID)
+	s.handle("GET", "/repos/{owner}/{repo}", s.getRepo)
+	s.handle("PATCH", "/repos/{owner}/{repo}", s.editRepo)
+	s.handle("DELETE", "/repos/{owner}/{repo}", s.deleteRepo)
+
+	s.handle("GET", "/repos/{owner}/{repo}/branches", s.listBranches)
+	s.handle("POST", "/repos/{owner}/{repo}/branches", s.createBranchHandler)
+	s.handle("GET", "/repos/{owner}/{repo}/branches/{branch}", s.getBranch)
+	s.handle("DELETE", "/repos/{owner}/{repo}/branches/{branch}", s.deleteBranch)
+}
+
+func (s *Server) listMyRepos(w http.ResponseWriter, r *http.Request, doer *user) error {
+	if err := requireUser(doer); err != nil {
+		return err
+	}
+	return s.renderRepos(w, r, doer, func(rp *repo) bool {
+		o := s.orgs[strings.ToLower(rp.Owner.UserName)]
+		return rp.Owner.ID == doer.ID || (o != nil && o.members[strings.ToLower(doer.UserName)])
+	})
+}
+
+func (s *Server) listUserRepos(w http.ResponseWriter, r *http.Request, doer *user) error {
+	u := s.users[strings.ToLower(r.PathValue("username"))]
+	if u == nil {
+		return errNotFound()
+	}
+	return s.renderRepos(w, r, doer, func(rp *repo) bool { return rp.Owner.ID == u.ID })
+}
+
+func (s *Server) listOrgRepos(w http.ResponseWriter, r *http.Request, doer *user) error {
+	o, err := s.orgFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return s.renderRepos(w, r, doer, func(rp *repo) bool { return rp.Owner.ID == o.ID })
+}
+
+func (s *Server) createRepoHandler(w http.ResponseWriter, r *http.Request, doer *user, owner *gitea.User) error {
+	var opt gitea.CreateRepoOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	rp, err := s.createRepo(owner, opt)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, rp.render(doer))
+}
+
+func (s *Server) createMyRepo(w http.ResponseWriter, r *http.Request, doer *user) error {
+	if err := requireUser(doer); err != nil {
+		return err
+	}
+	return s.createRepoHandler(w, r, doer, doer.render())
+}
+
+func (s *Server) createOrgRepo(w http.ResponseWriter, r *http.Request, doer *user) error {
+	o, err := s.orgFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	return s.createRepoHandler(w, r, doer, s.owner(o.UserName))
+}
+
+func (s *Server) getRepoByID(w http.ResponseWriter, r *http.Request, doer *user) error {
+	id, err := pathID(r, "id")
+	if err != nil {
+		return err
+	}
+	for _, rp := range s.repos {
+		if rp.ID == id && s.canRead(rp, doer) {
+			return writeJSON(w, http.StatusOK, rp.render(doer))
+		}
+	}
+	return errNotFound()
+}
+
+func (s *Server) getRepo(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, rp.render(doer))
+}
+
+func (s *Server) editRepo(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.EditRepoOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	if opt.Name != nil && *opt.Name != rp.Name {
+		if err := checkName(*opt.Name); err != nil {
+			return err
+		}
+		key := repoKey(rp.Owner.UserName, *opt.Name)
+		if s.repos[key] != nil {
+			return errorf(http.StatusUnprocessableEntity, "repository name is already taken")
+		}
+		delete(s.repos, repoKey(rp.Owner.UserName, rp.Name))
+		s.repos[key] = rp
+		rp.Name = *opt.Name
+		rp.FullName = rp.Owner.UserName + "/" + rp.Name
+		rp.HTMLURL = s.URL + "/" + rp.FullName
+		rp.CloneURL = rp.HTMLURL + ".git"
+	}
+	if opt.DefaultBranch != nil {
+		if rp.branch(*opt.DefaultBranch) == nil && !rp.Empty {
+			return errorf(http.StatusUnprocessableEntity, "branch %s does not exist", *opt.DefaultBranch)
+		}
+		rp.DefaultBranch = *opt.DefaultBranch
+	}
+	for _, field := range []struct {
+		value  *string
+		target *string
+	}{
+		{opt.Description, &rp.Description},
+		{opt.Website, &rp.Website},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+	for _, field := range []struct {
+		value  *bool
+		target *bool
+	}{
+		{opt.Private, &rp.Private},
+		{opt.Template, &rp.Template},
+		{opt.HasIssues, &rp.HasIssues},
+		{opt.HasWiki, &rp.HasWiki},
+		{opt.HasPullRequests, &rp.HasPullRequests},
+		{opt.HasProjects, &rp.HasProjects},
+		{opt.HasReleases, &rp.HasReleases},
+		{opt.HasPackages, &rp.HasPackages},
+		{opt.HasActions, &rp.HasActions},
+		{opt.AllowMerge, &rp.AllowMerge},
+		{opt.AllowRebase, &rp.AllowRebase},
+		{opt.AllowSquash, &rp.AllowSquash},
+		{opt.Archived, &rp.Archived},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+	rp.Updated = now()
+	return writeJSON(w, http.StatusOK, rp.render(doer))
+}
+
+func (s *Server) deleteRepo(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	delete(s.repos, repoKey(rp.Owner.UserName, rp.Name))
+	return writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) listBranches(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	result := make([]*gitea.Branch, 0, len(rp.branches))
+	for _, b := range paginate(w, r, rp.branches) {
+		result = append(result, renderBranch(b))
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) createBranchHandler(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.CreateBranchOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	b, err := rp.createBranch(opt)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, renderBranch(b))
+}
+
+func (s *Server) getBranch(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	b := rp.branch(r.PathValue("branch"))
+	if b == nil {
+		return errNotFound()
+	}
+	return writeJSON(w, http.StatusOK, renderBranch(b))
+}
+
+func (s *Server) deleteBranch(w http.ResponseWriter, r *http.Request, doer *user) error {
+	rp, err := s.repoFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	name := r.PathValue("branch")
+	if name == rp.DefaultBranch {
+		return errorf(http.StatusForbidden, "can not delete default branch")
+	}
+	for i, b := range rp.branches {
+		if b.Name == name {
+			rp.branches = append(rp.branches[:i], rp.branches[i+1:]...)
+			return writeJSON(w, http.StatusNoContent, nil)
+		}
+	}
+	return errNotFound()
+}
@@ -0,0 +1,393 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package giteatest provides an in-process fake of the Gitea API for unit tests
+// of code using a *gitea.Client, so no real Gitea instance is needed.
+//
+//	srv := giteatest.NewServer()
+//	defer srv.Close()
+//	srv.SeedUser("alice")
+//	srv.SeedRepo("alice", gitea.CreateRepoOption{Name: "demo", AutoInit: true})
+//	client := srv.Client("alice")
+//
+// The fake keeps its state in memory and implements the core of the API:
+// version, users, organizations, repositories, branches, issues, labels,
+// milestones, comments, pull requests, releases, webhooks including system webhooks
+// and Gitea Actions secrets and variables.
+// Requests to other endpoints are answered with 404.
+//
+// WebhookSender and WebhookFixture send signed webhooks like Gitea does, to test webhook receivers.
+package giteatest // import "code.gitea.io/sdk/gitea/giteatest"
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+const (
+	// AdminName is the name of the site administrator every Server is created with
+	AdminName = "gitea_admin"
+	// Password is the password of all users seeded by SeedUser
+	Password = "password"
+	// DefaultVersion is the Gitea version reported by a new Server
+	DefaultVersion = "1.22.0"
+
+	apiPrefix        = "/api/v1"
+	defaultPageSize  = 30
+	maxPageSize      = 50
+	errorDocumentURL = "https://gitea.com/api/swagger"
+)
+
+// Server is a fake Gitea server, all methods are safe for concurrent use
+type Server struct {
+	*httptest.Server
+
+	mutex   sync.Mutex
+	version string
+	routes  []route
+	lastID  int64
+
+	users  map[string]*user
+	tokens map[string]string
+	orgs   map[string]*org
+	repos  map[string]*repo
+
+	systemHooks []*gitea.Hook
+}
+
+// NewServer starts a fake Gitea server with a site administrator named AdminName.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		version: DefaultVersion,
+		users:   make(map[string]*user),
+		tokens:  make(map[string]string),
+		orgs:    make(map[string]*org),
+		repos:   make(map[string]*repo),
+	}
+	s.registerRoutes()
+	s.Server = httptest.NewServer(s)
+	s.SeedAdmin(AdminName)
+	return s
+}
+
+// SetVersion sets the Gitea version reported by the server
+func (s *Server) SetVersion(version string) {
+	s.mutex.Lock()
+	s.version = version
+	s.mutex.Unlock()
+}
+
+// Client returns a client authenticated with the token of the given user,
+// it panics if the user does not exist
+func (s *Server) Client(username string, opts ...gitea.ClientOption) *gitea.Client {
+	opts = append([]gitea.ClientOption{
+		gitea.SetToken(s.Token(username)),
+		gitea.SetHTTPClient(s.Server.Client()),
+	}, opts...)
+	client, err := gitea.NewClient(s.URL, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("giteatest: creating client: %v", err))
+	}
+	return client
+}
+
+// Token returns the access token of a user, it panics if the user does not exist
+func (s *Server) Token(username string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	u := s.users[strings.ToLower(username)]
+	if u == nil {
+		panic(fmt.Sprintf("giteatest: user %q does not exist", username))
+	}
+	return u.token
+}
+
+// apiError is an error answered with the given status code
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func errorf(status int, format string, args ...any) error {
+	return &apiError{status: status, message: fmt.Sprintf(format, args...)}
+}
+
+func errNotFound() error {
+	return errorf(http.StatusNotFound, "The target couldn't be found.")
+}
+
+// handler serves a request of the authenticated user, which is nil for anonymous requests
+type handler func(w http.ResponseWriter, r *http.Request, doer *user) error
+
+type route struct {
+	method   string
+	segments []string
+	handle   handler
+}
+
+// handle registers a handler for a method and a path relative to the api root,
+// path segments in braces are available by r.PathValue. The first matching route is used.
+func (s *Server) handle(method, pattern string, h handler) {
+	s.routes = append(s.routes, route{
+		method:   method,
+		segments: strings.Split(strings.Trim(pattern, "/"), "/"),
+		handle:   h,
+	})
+}
+
+func (s *Server) registerRoutes() {
+	s.handle("GET", "/version", s.getVersion)
+	s.registerUserRoutes()
+	s.registerRepoRoutes()
+	s.registerIssueRoutes()
+	s.registerPullRoutes()
+	s.registerReleaseRoutes()
+	s.registerHookRoutes()
+	s.registerActionRoutes()
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path, ok := strings.CutPrefix(r.URL.EscapedPath(), apiPrefix+"/")
+	if !ok {
+		writeError(w, errNotFound())
+		return
+	}
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	for i := range segments {
+		unescaped, err := url.PathUnescape(segments[i])
+		if err != nil {
+			writeError(w, errorf(http.StatusBadRequest, "invalid path: %v", err))
+			return
+		}
+		segments[i] = unescaped
+	}
+
+	methodAllowed := false
+	for _, rt := range s.routes {
+		if !rt.match(r, segments) {
+			continue
+		}
+		if rt.method != r.Method {
+			methodAllowed = true
+			continue
+		}
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		doer, err := s.authenticate(r)
+		if err == nil {
+			err = rt.handle(w, r, doer)
+		}
+		if err != nil {
+			writeError(w, err)
+		}
+		return
+	}
+	if methodAllowed {
+		writeError(w, errorf(http.StatusMethodNotAllowed, "method %s not allowed", r.Method))
+		return
+	}
+	writeError(w, errNotFound())
+}
+
+func (rt route) match(r *http.Request, segments []string) bool {
+	if len(rt.segments) != len(segments) {
+		return false
+	}
+	for i, seg := range rt.segments {
+		if !strings.HasPrefix(seg, "{") && seg != segments[i] {
+			return false
+		}
+	}
+	for i, seg := range rt.segments {
+		if strings.HasPrefix(seg, "{") {
+			r.SetPathValue(strings.Trim(seg, "{}"), segments[i])
+		}
+	}
+	return true
+}
+
+// authenticate returns the user of the request, using the token, basic auth and sudo
+func (s *Server) authenticate(r *http.Request) (*user, error) {
+	var doer *user
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = r.URL.Query().Get("access_token")
+	}
+	auth := r.Header.Get("Authorization")
+	switch {
+	case strings.HasPrefix(auth, "token "):
+		token = strings.TrimPrefix(auth, "token ")
+	case strings.HasPrefix(auth, "Bearer "):
+		token = strings.TrimPrefix(auth, "Bearer ")
+	case strings.HasPrefix(auth, "Basic "):
+		data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(auth, "Basic "))
+		if err != nil {
+			return nil, errorf(http.StatusUnauthorized, "invalid basic auth")
+		}
+		name, password, _ := strings.Cut(string(data), ":")
+		u := s.users[strings.ToLower(name)]
+		if u == nil || u.password != password {
+			return nil, errorf(http.StatusUnauthorized, "user does not exist or password is invalid")
+		}
+		doer = u
+	}
+	if token != "" {
+		doer = s.users[s.tokens[token]]
+		if doer == nil {
+			return nil, errorf(http.StatusUnauthorized, "invalid token")
+		}
+	}
+
+	if sudo := r.Header.Get("Sudo"); sudo != "" && doer != nil {
+		if !doer.IsAdmin {
+			return nil, errorf(http.StatusForbidden, "Only administrators allowed to sudo.")
+		}
+		doer = s.users[strings.ToLower(sudo)]
+		if doer == nil {
+			return nil, errNotFound()
+		}
+	}
+	return doer, nil
+}
+
+func (s *Server) getVersion(w http.ResponseWriter, _ *http.Request, _ *user) error {
+	return writeJSON(w, http.StatusOK, map[string]string{"version": s.version})
+}
+
+// nextID returns a new id, ids are unique across all kinds of objects
+func (s *Server) nextID() int64 {
+	s.lastID++
+	return s.lastID
+}
+
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) error {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	if v == nil {
+		return nil
+	}
+	_ = json.NewEncoder(w).Encode(v)
+	return nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if e, ok := err.(*apiError); ok {
+		status = e.status
+	}
+	_ = writeJSON(w, status, map[string]any{
+		"message": err.Error(),
+		"url":     errorDocumentURL,
+	})
+}
+
+// decode reads the json body of a request into v
+func decode(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errorf(http.StatusUnprocessableEntity, "invalid json body: %v", err)
+	}
+	return nil
+}
+
+func requireUser(doer *user) error {
+	if doer == nil {
+		return errorf(http.StatusUnauthorized, "token is required")
+	}
+	return nil
+}
+
+func requireAdmin(doer *user) error {
+	if err := requireUser(doer); err != nil {
+		return err
+	}
+	if !doer.IsAdmin {
+		return errorf(http.StatusForbidden, "reqSiteAdmin")
+	}
+	return nil
+}
+
+func pathID(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil {
+		return 0, errNotFound()
+	}
+	return id, nil
+}
+
+// paginate returns the page of items requested by the page and limit query parameters
+// and sets the X-Total-Count and Link headers like Gitea does
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T) []T {
+	query := r.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+
+	total := len(items)
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	lastPage := (total + limit - 1) / limit
+	var links []string
+	link := func(p int, rel string) {
+		query.Set("page", strconv.Itoa(p))
+		query.Set("limit", strconv.Itoa(limit))
+		u := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}
+		links = append(links, fmt.Sprintf("<%s>; rel=\"%s\"", u.String(), rel))
+	}
+	if page < lastPage {
+		link(page+1, "next")
+		link(lastPage, "last")
+	}
+	if page > 1 {
+		link(1, "first")
+		link(page-1, "prev")
+	}
+	if len(links) != 0 {
+		w.Header().Set("Link", strings.Join(links, ","))
+	}
+
+	start := (page - 1) * limit
+	if start >= total {
+		return []T{}
+	}
+	return items[start:min(start+limit, total)]
+}
+
+// sortedValues returns the values of a map ordered by their id
+func sortedValues[T any](m map[string]*T, id func(*T) int64) []*T {
+	values := make([]*T, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return id(values[i]) < id(values[j]) })
+	return values
+}
@@ -0,0 +1,259 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package giteatest
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerRepos(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SeedUser("alice")
+	srv.SeedUser("bob")
+	srv.SeedOrg("alice", "acme")
+	c := srv.Client("alice")
+
+	v, _, err := c.ServerVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultVersion, v)
+
+	me, _, err := c.GetMyUserInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", me.UserName)
+
+	repo, _, err := c.CreateOrgRepo("acme", gitea.CreateRepoOption{Name: "demo", AutoInit: true, Private: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "acme/demo", repo.FullName)
+	assert.Equal(t, "main", repo.DefaultBranch)
+
+	_, _, err = srv.Client("bob").GetRepo("acme", "demo")
+	assert.ErrorIs(t, err, gitea.ErrNotFound, "private repositories are hidden")
+
+	for i := 0; i < 5; i++ {
+		srv.SeedRepo("alice", gitea.CreateRepoOption{Name: "repo" + string(rune('a'+i))})
+	}
+	all, err := gitea.CollectAll(context.Background(), func(opt gitea.ListOptions) ([]*gitea.Repository, *gitea.Response, error) {
+		opt.PageSize = 2
+		return c.ListUserRepos("alice", gitea.ListReposOptions{ListOptions: opt})
+	}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, all, 5)
+
+	b, _, err := c.CreateBranch("acme", "demo", gitea.CreateBranchOption{BranchName: "feature"})
+	assert.NoError(t, err)
+	assert.Equal(t, "feature", b.Name)
+	branches, _, err := c.ListRepoBranches("acme", "demo", gitea.ListRepoBranchesOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, branches, 2)
+	deleted, _, err := c.DeleteRepoBranch("acme", "demo", "feature")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, _, err = c.DeleteRepoBranch("acme", "demo", "feature")
+	assert.ErrorIs(t, err, gitea.ErrNotFound)
+	assert.False(t, deleted)
+
+	_, _, err = srv.Client("bob").EditRepo("alice", "repoa", gitea.EditRepoOption{})
+	assert.ErrorIs(t, err, gitea.ErrForbidden)
+}
+
+func TestServerIssues(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SeedUser("alice")
+	srv.SeedRepo("alice", gitea.CreateRepoOption{Name: "demo", AutoInit: true})
+	bug := srv.SeedLabel("alice", "demo", gitea.CreateLabelOption{Name: "bug", Color: "#ff0000"})
+	ms := srv.SeedMilestone("alice", "demo", gitea.CreateMilestoneOption{Title: "v1"})
+	seeded := srv.SeedIssue("alice", "demo", "alice", gitea.CreateIssueOption{Title: "seeded"})
+	srv.SeedComment("alice", "demo", seeded.Index, "alice", "first")
+	c := srv.Client("alice")
+
+	issue, _, err := c.CreateIssue("alice", "demo", gitea.CreateIssueOption{
+		Title:     "broken",
+		Labels:    []int64{bug.ID},
+		Milestone: ms.ID,
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, issue.Index)
+	assert.Len(t, issue.Labels, 1)
+	assert.Equal(t, "v1", issue.Milestone.Title)
+
+	issues, _, err := c.ListRepoIssues("alice", "demo", gitea.ListIssueOption{Labels: []string{"bug"}})
+	assert.NoError(t, err)
+	if assert.Len(t, issues, 1) {
+		assert.Equal(t, "broken", issues[0].Title)
+	}
+
+	_, _, err = c.CreateIssueComment("alice", "demo", issue.Index, gitea.CreateIssueCommentOption{Body: "confirmed"})
+	assert.NoError(t, err)
+	comments, _, err := c.ListRepoIssueComments("alice", "demo", gitea.ListIssueCommentOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, comments, 2)
+
+	closed := gitea.StateClosed
+	_, _, err = c.EditIssue("alice", "demo", issue.Index, gitea.EditIssueOption{State: &closed})
+	assert.NoError(t, err)
+	ms, _, err = c.GetMilestone("alice", "demo", ms.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ms.ClosedIssues)
+
+	labels, _, err := c.ReplaceIssueLabels("alice", "demo", seeded.Index, gitea.IssueLabelsOption{Labels: []int64{bug.ID}})
+	assert.NoError(t, err)
+	assert.Len(t, labels, 1)
+	_, err = c.DeleteLabel("alice", "demo", bug.ID)
+	assert.NoError(t, err)
+	labels, _, err = c.GetIssueLabels("alice", "demo", seeded.Index, gitea.ListLabelsOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, labels)
+
+	_, _, err = c.GetIssue("alice", "demo", 99)
+	assert.ErrorIs(t, err, gitea.ErrNotFound)
+}
+
+func TestServerPullRequests(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SeedUser("alice")
+	srv.SeedRepo("alice", gitea.CreateRepoOption{Name: "demo"})
+	srv.SeedBranch("alice", "demo", "feature")
+	srv.SeedIssue("alice", "demo", "alice", gitea.CreateIssueOption{Title: "issue"})
+	c := srv.Client("alice")
+
+	pr, _, err := c.CreatePullRequest("alice", "demo", gitea.CreatePullRequestOption{Head: "feature", Base: "main", Title: "add feature"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, pr.Index, "pull requests share indexes with issues")
+	assert.True(t, pr.Mergeable)
+	assert.Equal(t, pr.Base.Sha, pr.Head.Sha)
+
+	_, _, err = c.CreatePullRequest("alice", "demo", gitea.CreatePullRequestOption{Head: "feature", Base: "main", Title: "again"})
+	assert.ErrorIs(t, err, gitea.ErrConflict)
+
+	merged, _, err := c.MergePullRequest("alice", "demo", pr.Index, gitea.MergePullRequestOption{Style: gitea.MergeStyleMerge, DeleteBranchAfterMerge: true})
+	assert.NoError(t, err)
+	assert.True(t, merged)
+	merged, _, err = c.IsPullRequestMerged("alice", "demo", pr.Index)
+	assert.NoError(t, err)
+	assert.True(t, merged)
+	merged, _, err = c.MergePullRequest("alice", "demo", pr.Index, gitea.MergePullRequestOption{Style: gitea.MergeStyleMerge})
+	assert.ErrorIs(t, err, gitea.ErrConflict, "merged PRs can not be merged again")
+	assert.False(t, merged)
+
+	pr, _, err = c.GetPullRequest("alice", "demo", pr.Index)
+	assert.NoError(t, err)
+	assert.True(t, pr.HasMerged)
+	assert.Equal(t, gitea.StateClosed, pr.State)
+	assert.NotNil(t, pr.MergedCommitID)
+	_, _, err = c.GetRepoBranch("alice", "demo", "feature")
+	assert.ErrorIs(t, err, gitea.ErrNotFound)
+
+	prs, _, err := c.ListRepoPullRequests("alice", "demo", gitea.ListPullRequestsOptions{State: gitea.StateAll})
+	assert.NoError(t, err)
+	assert.Len(t, prs, 1)
+	issues, _, err := c.ListRepoIssues("alice", "demo", gitea.ListIssueOption{Type: gitea.IssueTypeIssue, State: gitea.StateAll})
+	assert.NoError(t, err)
+	assert.Len(t, issues, 1)
+}
+
+func TestServerReleasesAndHooks(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SeedUser("alice")
+	srv.SeedRepo("alice", gitea.CreateRepoOption{Name: "demo", AutoInit: true})
+	srv.SeedRelease("alice", "demo", "alice", gitea.CreateReleaseOption{TagName: "v1.0.0"})
+	c := srv.Client("alice")
+
+	_, _, err := c.CreateRelease("alice", "demo", gitea.CreateReleaseOption{TagName: "v1.1.0-rc1", Title: "rc1", IsPrerelease: true})
+	assert.NoError(t, err)
+	latest, _, err := c.GetLatestRelease("alice", "demo")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.0.0", latest.TagName)
+	rel, _, err := c.GetReleaseByTag("alice", "demo", "v1.1.0-rc1")
+	assert.NoError(t, err)
+	assert.True(t, rel.IsPrerelease)
+	_, err = c.DeleteRelease("alice", "demo", rel.ID)
+	assert.NoError(t, err)
+
+	hook, _, err := c.CreateRepoHook("alice", "demo", gitea.CreateHookOption{
+		Type:   gitea.HookTypeGitea,
+		Config: map[string]string{"url": "https://example.com/hook", "content_type": "json", "secret": "s3cr3t"},
+		Active: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"push"}, hook.Events)
+	assert.NotContains(t, hook.Config, "secret")
+	_, err = c.TestRepoHook("alice", "demo", hook.ID, "main")
+	assert.NoError(t, err)
+	_, err = c.TestRepoHook("alice", "demo", hook.ID+100, "")
+	assert.ErrorIs(t, err, gitea.ErrNotFound)
+
+	_, _, err = c.CreateRepoHook("alice", "demo", gitea.CreateHookOption{Type: gitea.HookTypeGitea, Config: map[string]string{}})
+	assert.ErrorIs(t, err, gitea.ErrValidation)
+
+	active := false
+	_, err = c.EditRepoHook("alice", "demo", hook.ID, gitea.EditHookOption{Active: &active})
+	assert.NoError(t, err)
+	hook, _, err = c.GetRepoHook("alice", "demo", hook.ID)
+	assert.NoError(t, err)
+	assert.False(t, hook.Active)
+
+	_, _, err = c.CreateMyHook(gitea.CreateHookOption{Type: gitea.HookTypeSlack, Config: map[string]string{"url": "https://example.com"}})
+	assert.NoError(t, err)
+	hooks, _, err := c.ListMyHooks(gitea.ListHooksOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hooks, 1)
+
+	admin := srv.Client(AdminName)
+	_, _, err = c.AdminCreateHook(gitea.CreateHookOption{Type: gitea.HookTypeSlack, Config: map[string]string{"url": "https://example.com"}})
+	assert.ErrorIs(t, err, gitea.ErrForbidden)
+	hook, _, err = admin.AdminCreateHook(gitea.CreateHookOption{Type: gitea.HookTypeSlack, Config: map[string]string{"url": "https://example.com"}})
+	assert.NoError(t, err)
+	_, err = admin.AdminEditHook(hook.ID, gitea.EditHookOption{Events: []string{"release"}})
+	assert.NoError(t, err)
+	hook, _, err = admin.AdminGetHook(hook.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"release"}, hook.Events)
+	hooks, _, err = admin.AdminListHooks(gitea.AdminListHooksOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, hooks, 1)
+	_, err = admin.AdminDeleteHook(hook.ID)
+	assert.NoError(t, err)
+
+	srv.SetVersion("1.23.0")
+	_, _, err = srv.Client(AdminName).AdminListHooks(gitea.AdminListHooksOptions{Type: gitea.AdminHookTypeDefault})
+	assert.NoError(t, err)
+	srv.SetVersion("1.22.3")
+	_, _, err = srv.Client(AdminName).AdminListHooks(gitea.AdminListHooksOptions{Type: gitea.AdminHookTypeDefault})
+	assert.ErrorIs(t, err, &gitea.ErrServerVersionTooOld{}, "listing by type needs Gitea 1.23")
+}
+
+func TestServerAuth(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	srv.SeedUser("alice")
+
+	c, err := gitea.NewClient(srv.URL, gitea.SetGiteaVersion(""), gitea.SetBasicAuth("alice", "wrong"))
+	assert.NoError(t, err)
+	_, _, err = c.GetMyUserInfo()
+	assert.ErrorIs(t, err, gitea.ErrUnauthorized)
+
+	admin := srv.Client(AdminName, gitea.SetSudo("alice"))
+	me, _, err := admin.GetMyUserInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", me.UserName)
+
+	_, _, err = srv.Client("alice").AdminListUsers(gitea.AdminListUsersOptions{})
+	assert.ErrorIs(t, err, gitea.ErrForbidden)
+
+	resp, err := http.Post(srv.URL+"/api/v1/user/repos", "application/json", nil)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
@@ -0,0 +1,367 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package giteatest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+type user struct {
+	gitea.User
+	password string
+	token    string
+	hooks    []*gitea.Hook
+	secrets  map[string]*secret
+	vars     map[string]*gitea.Variable
+}
+
+type org struct {
+	gitea.Organization
+	// members are the lower case names of the members
+	members map[string]bool
+	hooks   []*gitea.Hook
+	secrets map[string]*secret
+	vars    map[string]*gitea.Variable
+}
+
+// SeedUser creates a user with the password Password and an access token, see Token.
+// It panics if the name is invalid or already taken.
+func (s *Server) SeedUser(name string) *gitea.User {
+	return s.seedUser(name, false)
+}
+
+// SeedAdmin creates a site administrator like SeedUser
+func (s *Server) SeedAdmin(name string) *gitea.User {
+	return s.seedUser(name, true)
+}
+
+func (s *Server) seedUser(name string, admin bool) *gitea.User {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	u, err := s.createUser(name, name+"@example.com", Password, admin)
+	must(err)
+	return u.render()
+}
+
+// SeedOrg creates an organization with owner as its first member.
+// It panics if the owner does not exist or the name is invalid or already taken.
+func (s *Server) SeedOrg(owner, name string) *gitea.Organization {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	o, err := s.createOrg(s.users[strings.ToLower(owner)], gitea.CreateOrgOption{Name: name})
+	must(err)
+	return o.render()
+}
+
+// AddOrgMember adds a user to an organization, members can write to all repositories of it.
+// It panics if the organization or user does not exist.
+func (s *Server) AddOrgMember(orgName, username string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	o := s.orgs[strings.ToLower(orgName)]
+	if o == nil || s.users[strings.ToLower(username)] == nil {
+		must(errNotFound())
+	}
+	o.members[strings.ToLower(username)] = true
+}
+
+// must panics with err of a seeding helper
+func must(err error) {
+	if err != nil {
+		panic(fmt.Sprintf("giteatest: %v", err))
+	}
+}
+
+func (u *user) render() *gitea.User {
+	cp := u.User
+	return &cp
+}
+
+func (o *org) render() *gitea.Organization {
+	cp := o.Organization
+	return &cp
+}
+
+// checkName validates the name of a new user, organization or repository
+func checkName(name string) error {
+	if name == "" || strings.ContainsAny(name, "/ ") || name == "." || name == ".." {
+		return errorf(http.StatusUnprocessableEntity, "name %q is invalid", name)
+	}
+	return nil
+}
+
+func (s *Server) checkOwnerName(name string) error {
+	if err := checkName(name); err != nil {
+		return err
+	}
+	if s.users[strings.ToLower(name)] != nil || s.orgs[strings.ToLower(name)] != nil {
+		return errorf(http.StatusUnprocessableEntity, "user already exists [name: %s]", name)
+	}
+	return nil
+}
+
+func (s *Server) createUser(name, email, password string, admin bool) (*user, error) {
+	if err := s.checkOwnerName(name); err != nil {
+		return nil, err
+	}
+	id := s.nextID()
+	u := &user{
+		User: gitea.User{
+			ID:         id,
+			UserName:   name,
+			Email:      email,
+			IsAdmin:    admin,
+			IsActive:   true,
+			Created:    now(),
+			Visibility: gitea.VisibleTypePublic,
+		},
+		password: password,
+		token:    fakeSHA("token", id, name),
+	}
+	s.users[strings.ToLower(name)] = u
+	s.tokens[u.token] = strings.ToLower(name)
+	return u, nil
+}
+
+func (s *Server) createOrg(owner *user, opt gitea.CreateOrgOption) (*org, error) {
+	if owner == nil {
+		return nil, errNotFound()
+	}
+	if err := s.checkOwnerName(opt.Name); err != nil {
+		return nil, err
+	}
+	visibility := opt.Visibility
+	if visibility == "" {
+		visibility = gitea.VisibleTypePublic
+	}
+	o := &org{
+		Organization: gitea.Organization{
+			ID:          s.nextID(),
+			UserName:    opt.Name,
+			FullName:    opt.FullName,
+			Description: opt.Description,
+			Website:     opt.Website,
+			Location:    opt.Location,
+			Visibility:  string(visibility),
+		},
+		members: map[string]bool{strings.ToLower(owner.UserName): true},
+	}
+	s.orgs[strings.ToLower(opt.Name)] = o
+	return o, nil
+}
+
+// owner returns the user representation of a user or an organization
+func (s *Server) owner(name string) *gitea.User {
+	if u := s.users[strings.ToLower(name)]; u != nil {
+		return u.render()
+	}
+	if o := s.orgs[strings.ToLower(name)]; o != nil {
+		return &gitea.User{
+			ID:          o.ID,
+			UserName:    o.UserName,
+			FullName:    o.FullName,
+			Description: o.Description,
+			Website:     o.Website,
+			Location:    o.Location,
+			Visibility:  gitea.VisibleType(o.Visibility),
+		}
+	}
+	return nil
+}
+
+func (s *Server) registerUserRoutes() {
+	s.handle("GET", "/user", s.getAuthenticatedUser)
+	s.handle("GET", "/users/{username}", s.getUser)
+	s.handle("GET", "/admin/users", s.adminListUsers)
+	s.handle("POST", "/admin/users", s.adminCreateUser)
+	s.handle("DELETE", "/admin/users/{username}", s.adminDeleteUser)
+
+	s.handle("GET", "/user/orgs", s.listMyOrgs)
+	s.handle("GET", "/users/{username}/orgs", s.listUserOrgs)
+	s.handle("POST", "/orgs", s.createOrgHandler)
+	s.handle("GET", "/orgs/{org}", s.getOrg)
+	s.handle("PATCH", "/orgs/{org}", s.editOrg)
+	s.handle("DELETE", "/orgs/{org}", s.deleteOrg)
+}
+
+func (s *Server) getAuthenticatedUser(w http.ResponseWriter, _ *http.Request, doer *user) error {
+	if err := requireUser(doer); err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, doer.render())
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request, _ *user) error {
+	u := s.owner(r.PathValue("username"))
+	if u == nil {
+		return errNotFound()
+	}
+	return writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) adminListUsers(w http.ResponseWriter, r *http.Request, doer *user) error {
+	if err := requireAdmin(doer); err != nil {
+		return err
+	}
+	users := sortedValues(s.users, func(u *user) int64 { return u.ID })
+	result := make([]*gitea.User, 0, len(users))
+	for _, u := range paginate(w, r, users) {
+		result = append(result, u.render())
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) adminCreateUser(w http.ResponseWriter, r *http.Request, doer *user) error {
+	if err := requireAdmin(doer); err != nil {
+		return err
+	}
+	var opt gitea.CreateUserOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	if opt.Email == "" {
+		return errorf(http.StatusUnprocessableEntity, "email is required")
+	}
+	u, err := s.createUser(opt.Username, opt.Email, opt.Password, false)
+	if err != nil {
+		return err
+	}
+	u.FullName = opt.FullName
+	u.LoginName = opt.LoginName
+	u.SourceID = opt.SourceID
+	if opt.Visibility != nil {
+		u.Visibility = *opt.Visibility
+	}
+	return writeJSON(w, http.StatusCreated, u.render())
+}
+
+func (s *Server) adminDeleteUser(w http.ResponseWriter, r *http.Request, doer *user) error {
+	if err := requireAdmin(doer); err != nil {
+		return err
+	}
+	name := strings.ToLower(r.PathValue("username"))
+	u := s.users[name]
+	if u == nil {
+		return errNotFound()
+	}
+	for _, rp := range s.repos {
+		if strings.EqualFold(rp.Owner.UserName, name) {
+			return errorf(http.StatusUnprocessableEntity, "user still owns one or more repositories")
+		}
+	}
+	delete(s.tokens, u.token)
+	delete(s.users, name)
+	for _, o := range s.orgs {
+		delete(o.members, name)
+	}
+	return writeJSON(w, http.StatusNoContent, nil)
+}
+
+func (s *Server) listOrgsOf(w http.ResponseWriter, r *http.Request, name string) error {
+	orgs := sortedValues(s.orgs, func(o *org) int64 { return o.ID })
+	result := make([]*gitea.Organization, 0, len(orgs))
+	var member []*org
+	for _, o := range orgs {
+		if o.members[strings.ToLower(name)] {
+			member = append(member, o)
+		}
+	}
+	for _, o := range paginate(w, r, member) {
+		result = append(result, o.render())
+	}
+	return writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) listMyOrgs(w http.ResponseWriter, r *http.Request, doer *user) error {
+	if err := requireUser(doer); err != nil {
+		return err
+	}
+	return s.listOrgsOf(w, r, doer.UserName)
+}
+
+func (s *Server) listUserOrgs(w http.ResponseWriter, r *http.Request, _ *user) error {
+	name := r.PathValue("username")
+	if s.users[strings.ToLower(name)] == nil {
+		return errNotFound()
+	}
+	return s.listOrgsOf(w, r, name)
+}
+
+func (s *Server) createOrgHandler(w http.ResponseWriter, r *http.Request, doer *user) error {
+	if err := requireUser(doer); err != nil {
+		return err
+	}
+	var opt gitea.CreateOrgOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	o, err := s.createOrg(doer, opt)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusCreated, o.render())
+}
+
+// orgFor returns the organization of the request, for writing only to members
+func (s *Server) orgFor(r *http.Request, doer *user, write bool) (*org, error) {
+	o := s.orgs[strings.ToLower(r.PathValue("org"))]
+	if o == nil {
+		return nil, errNotFound()
+	}
+	if write {
+		if err := requireUser(doer); err != nil {
+			return nil, err
+		}
+		if !doer.IsAdmin && !o.members[strings.ToLower(doer.UserName)] {
+			return nil, errorf(http.StatusForbidden, "user is no member of the organization")
+		}
+	}
+	return o, nil
+}
+
+func (s *Server) getOrg(w http.ResponseWriter, r *http.Request, doer *user) error {
+	o, err := s.orgFor(r, doer, false)
+	if err != nil {
+		return err
+	}
+	return writeJSON(w, http.StatusOK, o.render())
+}
+
+func (s *Server) editOrg(w http.ResponseWriter, r *http.Request, doer *user) error {
+	o, err := s.orgFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	var opt gitea.EditOrgOption
+	if err := decode(r, &opt); err != nil {
+		return err
+	}
+	o.FullName = opt.FullName
+	o.Description = opt.Description
+	o.Website = opt.Website
+	o.Location = opt.Location
+	if opt.Visibility != "" {
+		o.Visibility = string(opt.Visibility)
+	}
+	return writeJSON(w, http.StatusOK, o.render())
+}
+
+func (s *Server) deleteOrg(w http.ResponseWriter, r *http.Request, doer *user) error {
+	o, err := s.orgFor(r, doer, true)
+	if err != nil {
+		return err
+	}
+	for _, rp := range s.repos {
+		if rp.Owner.ID == o.ID {
+			return errorf(http.StatusUnprocessableEntity, "organization still owns one or more repositories")
+		}
+	}
+	delete(s.orgs, strings.ToLower(o.UserName))
+	return writeJSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,318 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package giteatest
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// WebhookSender sends webhooks like Gitea does, to test webhook receivers:
+//
+//	sender := &giteatest.WebhookSender{Secret: "s3cr3t"}
+//	rec, err := sender.Serve(handler, gitea.HookEventPush, giteatest.WebhookFixture(gitea.HookEventPush))
+type WebhookSender struct {
+	// Secret signs the payloads, no signature is sent if it is empty
+	Secret string
+	// Client sends the webhooks of Send, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// NewRequest creates a webhook request with the JSON encoded payload and the headers Gitea sends,
+// including the X-Gitea-Signature header checked by gitea.VerifyWebhookSignature
+func (s *WebhookSender) NewRequest(ctx context.Context, url string, event gitea.HookEventType, payload any) (*http.Request, error) {
+	body, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	delivery, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GiteaServer")
+	for _, prefix := range []string{"X-Gitea-", "X-Gogs-", "X-GitHub-"} {
+		req.Header.Set(prefix+"Delivery", delivery)
+		req.Header.Set(prefix+"Event", event.Event())
+		req.Header.Set(prefix+"Event-Type", string(event))
+	}
+	if s.Secret != "" {
+		hash := hmac.New(sha256.New, []byte(s.Secret))
+		hash.Write(body)
+		signature := hex.EncodeToString(hash.Sum(nil))
+		req.Header.Set("X-Gitea-Signature", signature)
+		req.Header.Set("X-Gogs-Signature", signature)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+signature)
+	}
+	return req, nil
+}
+
+// Send posts a webhook to url, the caller must close the body of the response
+func (s *WebhookSender) Send(ctx context.Context, url string, event gitea.HookEventType, payload any) (*http.Response, error) {
+	req, err := s.NewRequest(ctx, url, event, payload)
+	if err != nil {
+		return nil, err
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// Serve passes a webhook directly to handler and returns the recorded response
+func (s *WebhookSender) Serve(handler http.Handler, event gitea.HookEventType, payload any) (*httptest.ResponseRecorder, error) {
+	req, err := s.NewRequest(context.Background(), "http://localhost/", event, payload)
+	if err != nil {
+		return nil, err
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec, nil
+}
+
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// WebhookFixture returns a realistic payload of an event, as sent by Gitea for the repository
+// "alice/demo", e.g. a *gitea.PushPayload for gitea.HookEventPush. The payloads of the variants
+// of issue and pull request events carry the matching action. It returns nil for unknown events.
+// Every call returns new values, so they can be modified.
+func WebhookFixture(event gitea.HookEventType) any {
+	f := newFixtures()
+	switch event {
+	case gitea.HookEventCreate:
+		return &gitea.CreatePayload{Sha: f.commit.ID, Ref: "feature", RefType: "branch", Repo: f.repo, Sender: f.alice}
+	case gitea.HookEventDelete:
+		return &gitea.DeletePayload{Ref: "feature", RefType: "branch", PusherType: gitea.PusherTypeUser, Repo: f.repo, Sender: f.alice}
+	case gitea.HookEventFork:
+		fork := *f.repo
+		fork.ID, fork.Owner, fork.FullName, fork.Fork, fork.Parent = 2, f.bob, "bob/demo", true, f.repo
+		fork.HTMLURL = strings.Replace(f.repo.HTMLURL, "/alice/", "/bob/", 1)
+		fork.SSHURL = strings.Replace(f.repo.SSHURL, ":alice/", ":bob/", 1)
+		fork.CloneURL = strings.Replace(f.repo.CloneURL, "/alice/", "/bob/", 1)
+		return &gitea.ForkPayload{Forkee: &fork, Repo: f.repo, Sender: f.bob}
+	case gitea.HookEventPush:
+		return &gitea.PushPayload{
+			Ref:          "refs/heads/main",
+			Before:       fakeSHA("before"),
+			After:        f.commit.ID,
+			CompareURL:   f.repo.HTMLURL + "/compare/" + fakeSHA("before") + "..." + f.commit.ID,
+			Commits:      []*gitea.PayloadCommit{f.commit},
+			TotalCommits: 1,
+			HeadCommit:   f.commit,
+			Repo:         f.repo,
+			Pusher:       f.alice,
+			Sender:       f.alice,
+		}
+	case gitea.HookEventIssues:
+		return f.issuePayload(gitea.HookIssueOpened)
+	case gitea.HookEventIssueAssign:
+		p := f.issuePayload(gitea.HookIssueAssigned)
+		p.Issue.Assignees = []*gitea.User{f.bob}
+		return p
+	case gitea.HookEventIssueLabel:
+		p := f.issuePayload(gitea.HookIssueLabelUpdated)
+		p.Issue.Labels = []*gitea.Label{f.label}
+		return p
+	case gitea.HookEventIssueMilestone:
+		p := f.issuePayload(gitea.HookIssueMilestoned)
+		p.Issue.Milestone = f.milestone
+		return p
+	case gitea.HookEventIssueComment:
+		return &gitea.IssueCommentPayload{Action: gitea.HookIssueCommentCreated, Issue: f.issue, Comment: f.comment, Repository: f.repo, Sender: f.bob}
+	case gitea.HookEventPullRequestComment:
+		// the issue of a pull request
+		issue := *f.issue
+		issue.ID, issue.Index, issue.Title, issue.Body = f.pull.ID, f.pull.Index, f.pull.Title, f.pull.Body
+		issue.URL, issue.HTMLURL = f.pull.URL, f.pull.HTMLURL
+		issue.PullRequest = &gitea.PullRequestMeta{}
+		f.comment.HTMLURL = f.pull.HTMLURL + "#issuecomment-1"
+		f.comment.IssueURL, f.comment.PRURL = "", f.pull.HTMLURL
+		return &gitea.IssueCommentPayload{
+			Action: gitea.HookIssueCommentCreated, Issue: &issue, PullRequest: f.pull, Comment: f.comment,
+			Repository: f.repo, Sender: f.bob, IsPull: true,
+		}
+	case gitea.HookEventPullRequest:
+		return f.pullPayload(gitea.HookIssueOpened)
+	case gitea.HookEventPullRequestAssign:
+		p := f.pullPayload(gitea.HookIssueAssigned)
+		p.PullRequest.Assignee, p.PullRequest.Assignees = f.bob, []*gitea.User{f.bob}
+		return p
+	case gitea.HookEventPullRequestLabel:
+		p := f.pullPayload(gitea.HookIssueLabelUpdated)
+		p.PullRequest.Labels = []*gitea.Label{f.label}
+		return p
+	case gitea.HookEventPullRequestMilestone:
+		p := f.pullPayload(gitea.HookIssueMilestoned)
+		p.PullRequest.Milestone = f.milestone
+		return p
+	case gitea.HookEventPullRequestSync:
+		p := f.pullPayload(gitea.HookIssueSynchronized)
+		p.CommitID = f.pull.Head.Sha
+		return p
+	case gitea.HookEventPullRequestReviewRequest:
+		p := f.pullPayload(gitea.HookIssueReviewRequested)
+		p.RequestedReviewer = f.bob
+		return p
+	case gitea.HookEventPullRequestReviewApproved, gitea.HookEventPullRequestReviewRejected, gitea.HookEventPullRequestReviewComment:
+		p := f.pullPayload(gitea.HookIssueReviewed)
+		p.Sender = f.bob
+		p.Review = &gitea.ReviewPayload{Type: string(event), Content: reviewComments[event]}
+		return p
+	case gitea.HookEventWiki:
+		return &gitea.WikiPayload{Action: gitea.HookWikiCreated, Repository: f.repo, Sender: f.alice, Page: "Home", Comment: "Add home page"}
+	case gitea.HookEventRepository:
+		return &gitea.RepositoryPayload{Action: gitea.HookRepoCreated, Repository: f.repo, Organization: f.alice, Sender: f.alice}
+	case gitea.HookEventRelease:
+		return &gitea.ReleasePayload{Action: gitea.HookReleasePublished, Release: f.release, Repository: f.repo, Sender: f.alice}
+	case gitea.HookEventPackage:
+		return &gitea.PackagePayload{
+			Action:     gitea.HookPackageCreated,
+			Repository: f.repo,
+			Package: &gitea.Package{
+				ID: 1, Owner: *f.alice, Repository: f.repo, Creator: *f.alice,
+				Type: "generic", Name: "demo", Version: "1.0.0", CreatedAt: f.now,
+			},
+			Sender: f.alice,
+		}
+	case gitea.HookEventStatus:
+		return &gitea.CommitStatusPayload{
+			ID: 1, SHA: f.commit.ID, State: "success", Context: "ci/build", Description: "Build succeeded",
+			TargetURL: "https://ci.example.com/builds/1", Commit: f.commit, Repo: f.repo, Sender: f.alice, CreatedAt: f.now,
+		}
+	case gitea.HookEventWorkflowRun:
+		return &gitea.WorkflowRunPayload{Action: "completed", Workflow: f.workflow, WorkflowRun: f.run, Repo: f.repo, Sender: f.alice}
+	case gitea.HookEventWorkflowJob:
+		return &gitea.WorkflowJobPayload{Action: "completed", WorkflowJob: f.job, Repo: f.repo, Sender: f.alice}
+	}
+	return nil
+}
+
+// reviewComments are the contents of the review fixtures
+var reviewComments = map[gitea.HookEventType]string{
+	gitea.HookEventPullRequestReviewApproved: "Looks good to me",
+	gitea.HookEventPullRequestReviewRejected: "Please add a test",
+	gitea.HookEventPullRequestReviewComment:  "Why is this needed?",
+}
+
+// fixtures are the values payloads of WebhookFixture are made of
+type fixtures struct {
+	now       time.Time
+	alice     *gitea.User
+	bob       *gitea.User
+	repo      *gitea.Repository
+	commit    *gitea.PayloadCommit
+	label     *gitea.Label
+	milestone *gitea.Milestone
+	issue     *gitea.Issue
+	pull      *gitea.PullRequest
+	comment   *gitea.Comment
+	release   *gitea.Release
+	workflow  *gitea.ActionWorkflow
+	run       *gitea.ActionWorkflowRun
+	job       *gitea.ActionWorkflowJob
+}
+
+func newFixtures() *fixtures {
+	const baseURL = "https://gitea.example.com"
+	f := &fixtures{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	f.alice = &gitea.User{ID: 1, UserName: "alice", FullName: "Alice", Email: "alice@example.com",
+		AvatarURL: baseURL + "/avatars/1", IsActive: true, Created: f.now, Visibility: gitea.VisibleTypePublic}
+	f.bob = &gitea.User{ID: 2, UserName: "bob", FullName: "Bob", Email: "bob@example.com",
+		AvatarURL: baseURL + "/avatars/2", IsActive: true, Created: f.now, Visibility: gitea.VisibleTypePublic}
+	f.repo = &gitea.Repository{
+		ID: 1, Owner: f.alice, Name: "demo", FullName: "alice/demo", Description: "A demo repository",
+		HTMLURL: baseURL + "/alice/demo", SSHURL: "git@gitea.example.com:alice/demo.git", CloneURL: baseURL + "/alice/demo.git",
+		DefaultBranch: "main", Created: f.now, Updated: f.now, HasIssues: true, HasPullRequests: true,
+	}
+	f.commit = &gitea.PayloadCommit{
+		ID:        fakeSHA("commit"),
+		Message:   "Fix the build\n",
+		URL:       f.repo.HTMLURL + "/commit/" + fakeSHA("commit"),
+		Author:    &gitea.PayloadUser{Name: "Alice", Email: "alice@example.com", UserName: "alice"},
+		Committer: &gitea.PayloadUser{Name: "Alice", Email: "alice@example.com", UserName: "alice"},
+		Timestamp: f.now,
+		Modified:  []string{"main.go"},
+	}
+	f.label = &gitea.Label{ID: 1, Name: "bug", Color: "ee0701", URL: baseURL + "/api/v1/repos/alice/demo/labels/1"}
+	f.milestone = &gitea.Milestone{ID: 1, Title: "v1.0", State: gitea.StateOpen, OpenIssues: 1, Created: f.now}
+	f.issue = &gitea.Issue{
+		ID: 1, Index: 1, URL: baseURL + "/api/v1/repos/alice/demo/issues/1", HTMLURL: f.repo.HTMLURL + "/issues/1",
+		Poster: f.alice, Title: "The build is broken", Body: "It fails on main.", State: gitea.StateOpen,
+		Created: f.now, Updated: f.now, Repository: &gitea.RepositoryMeta{ID: 1, Name: "demo", Owner: "alice", FullName: "alice/demo"},
+	}
+	head := fakeSHA("head")
+	f.pull = &gitea.PullRequest{
+		ID: 2, Index: 2, URL: baseURL + "/api/v1/repos/alice/demo/pulls/2", HTMLURL: f.repo.HTMLURL + "/pulls/2",
+		DiffURL: f.repo.HTMLURL + "/pulls/2.diff", PatchURL: f.repo.HTMLURL + "/pulls/2.patch",
+		Poster: f.alice, Title: "Fix the build", Body: "Fixes #1", State: gitea.StateOpen, Mergeable: true,
+		Base:      &gitea.PRBranchInfo{Name: "main", Ref: "main", Sha: f.commit.ID, RepoID: 1, Repository: f.repo},
+		Head:      &gitea.PRBranchInfo{Name: "fix-build", Ref: "fix-build", Sha: head, RepoID: 1, Repository: f.repo},
+		MergeBase: f.commit.ID, Created: &f.now, Updated: &f.now,
+	}
+	f.comment = &gitea.Comment{
+		ID: 1, HTMLURL: f.repo.HTMLURL + "/issues/1#issuecomment-1", IssueURL: f.issue.HTMLURL,
+		Poster: f.bob, Body: "I can reproduce this.", Created: f.now, Updated: f.now,
+	}
+	f.release = &gitea.Release{
+		ID: 1, TagName: "v1.0.0", Target: "main", Title: "v1.0.0", Note: "First release",
+		URL: baseURL + "/api/v1/repos/alice/demo/releases/1", HTMLURL: f.repo.HTMLURL + "/releases/tag/v1.0.0",
+		TarURL: f.repo.HTMLURL + "/archive/v1.0.0.tar.gz", ZipURL: f.repo.HTMLURL + "/archive/v1.0.0.zip",
+		CreatedAt: f.now, PublishedAt: f.now, Publisher: f.alice,
+	}
+	f.workflow = &gitea.ActionWorkflow{
+		ID: "build.yml", Name: "build", Path: ".gitea/workflows/build.yml", State: gitea.ActionWorkflowStateActive,
+		HTMLURL: f.repo.HTMLURL + "/actions?workflow=build.yml", CreatedAt: f.now, UpdatedAt: f.now,
+	}
+	f.run = &gitea.ActionWorkflowRun{
+		ID: 1, HTMLURL: f.repo.HTMLURL + "/actions/runs/1", DisplayTitle: "Fix the build", Path: "build.yml@refs/heads/main",
+		Event: "push", RunAttempt: 1, RunNumber: 1, RepositoryID: 1, HeadSha: f.commit.ID, HeadBranch: "main",
+		Status: "completed", Conclusion: "success", Actor: f.alice, TriggerActor: f.alice, Repository: f.repo,
+		StartedAt: f.now, CompletedAt: f.now.Add(time.Minute),
+	}
+	f.job = &gitea.ActionWorkflowJob{
+		ID: 1, HTMLURL: f.run.HTMLURL + "/jobs/0", RunID: 1, RunURL: f.run.HTMLURL, Name: "build",
+		Labels: []string{"ubuntu-latest"}, RunAttempt: 1, HeadSha: f.commit.ID, HeadBranch: "main",
+		Status: "completed", Conclusion: "success", RunnerID: 1, RunnerName: "runner-1",
+		Steps: []*gitea.ActionWorkflowStep{
+			{Name: "Checkout", Number: 1, Status: "completed", Conclusion: "success", StartedAt: f.now, CompletedAt: f.now},
+			{Name: "Build", Number: 2, Status: "completed", Conclusion: "success", StartedAt: f.now, CompletedAt: f.now.Add(time.Minute)},
+		},
+		CreatedAt: f.now, StartedAt: f.now, CompletedAt: f.now.Add(time.Minute),
+	}
+	return f
+}
+
+func (f *fixtures) issuePayload(action gitea.HookIssueAction) *gitea.IssuePayload {
+	return &gitea.IssuePayload{Action: action, Index: f.issue.Index, Issue: f.issue, Repository: f.repo, Sender: f.alice}
+}
+
+func (f *fixtures) pullPayload(action gitea.HookIssueAction) *gitea.PullRequestPayload {
+	return &gitea.PullRequestPayload{Action: action, Index: f.pull.Index, PullRequest: f.pull, Repository: f.repo, Sender: f.alice}
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package giteatest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSender(t *testing.T) {
+	var parsed any
+	var header http.Header
+	handler := gitea.VerifyWebhookSignatureMiddleware("s3cr3t")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		header = r.Header
+		parsed, err = gitea.ParseWebhook(r)
+		assert.NoError(t, err)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	sender := &WebhookSender{Secret: "s3cr3t"}
+
+	for _, event := range gitea.WebhookEventTypes() {
+		fixture := WebhookFixture(event)
+		if !assert.NotNil(t, fixture, event) {
+			continue
+		}
+		rec, err := sender.Serve(handler, event, fixture)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code, event)
+		assert.Equal(t, reflect.TypeOf(fixture), reflect.TypeOf(parsed), event)
+		assert.Equal(t, fixture, parsed, event)
+		assert.Equal(t, event.Event(), header.Get("X-Gitea-Event"))
+		assert.Equal(t, string(event), header.Get("X-Gitea-Event-Type"))
+		assert.Len(t, header.Get("X-Gitea-Delivery"), 36)
+	}
+	assert.Nil(t, WebhookFixture("unknown"))
+
+	pr := WebhookFixture(gitea.HookEventPullRequestReviewRejected).(*gitea.PullRequestPayload)
+	assert.Equal(t, gitea.HookIssueReviewed, pr.Action)
+	assert.Equal(t, "pull_request_review_rejected", pr.Review.Type)
+
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+	resp, err := (&WebhookSender{Secret: "wrong"}).Send(context.Background(), srv.URL, gitea.HookEventPush, WebhookFixture(gitea.HookEventPush))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	resp, err = sender.Send(context.Background(), srv.URL, gitea.HookEventPush, WebhookFixture(gitea.HookEventPush))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"time"
 )
 
@@ -22,6 +23,9 @@ type Hook struct {
 	Active  bool              `json:"active"`
 	Updated time.Time         `json:"updated_at"`
 	Created time.Time         `json:"created_at"`
+
+	BranchFilter        string `json:"branch_filter"`
+	AuthorizationHeader string `json:"authorization_header"`
 }
 
 // HookType represent all webhook types gitea currently offer
@@ -44,6 +48,12 @@ const (
 	HookTypeTelegram HookType = "telegram"
 	// HookTypeFeishu webhook that feishu understand
 	HookTypeFeishu HookType = "feishu"
+	// HookTypeMatrix webhook that matrix understand
+	HookTypeMatrix HookType = "matrix"
+	// HookTypeWechatwork webhook that wechatwork understand
+	HookTypeWechatwork HookType = "wechatwork"
+	// HookTypePackagist webhook that packagist understand
+	HookTypePackagist HookType = "packagist"
 )
 
 // ListHooksOptions options for listing hooks
@@ -126,6 +136,16 @@ func (opt CreateHookOption) Validate() error {
 	return nil
 }
 
+// ValidateStrict validates the CreateHookOption struct like Validate, and also checks the
+// events against HookEventTypes, the branch filter and the authorization header.
+// Events added in newer Gitea versions than known to the SDK are rejected.
+func (opt CreateHookOption) ValidateStrict() error {
+	if err := opt.Validate(); err != nil {
+		return err
+	}
+	return validateHookOptions(opt.Events, opt.BranchFilter, opt.AuthorizationHeader)
+}
+
 // CreateOrgHook create one hook for an organization, with options
 func (c *Client) CreateOrgHook(org string, opt CreateHookOption) (*Hook, *Response, error) {
 	if err := escapeValidatePathSegments(&org); err != nil {
@@ -180,6 +200,13 @@ type EditHookOption struct {
 	AuthorizationHeader string            `json:"authorization_header"`
 }
 
+// ValidateStrict checks the events of the EditHookOption struct against HookEventTypes,
+// its branch filter and its authorization header.
+// Events added in newer Gitea versions than known to the SDK are rejected.
+func (opt EditHookOption) ValidateStrict() error {
+	return validateHookOptions(opt.Events, opt.BranchFilter, opt.AuthorizationHeader)
+}
+
 // EditOrgHook modify one hook of an organization, with hook id and options
 func (c *Client) EditOrgHook(org string, id int64, opt EditHookOption) (*Response, error) {
 	if err := escapeValidatePathSegments(&org); err != nil {
@@ -239,3 +266,19 @@ func (c *Client) DeleteRepoHook(user, repo string, id int64) (*Response, error)
 	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/repos/%s/%s/hooks/%d", user, repo, id), nil, nil)
 	return resp, err
 }
+
+// TestRepoHook makes the server send a test delivery of a push event to a repository hook,
+// the payload contains the last commit of ref or of the default branch if ref is empty.
+// Gitea has no API to list the deliveries of a hook or to redeliver them, so the
+// outcome is only visible in the settings of the hook.
+func (c *Client) TestRepoHook(user, repo string, id int64, ref string) (*Response, error) {
+	if err := escapeValidatePathSegments(&user, &repo); err != nil {
+		return nil, err
+	}
+	link := fmt.Sprintf("/repos/%s/%s/hooks/%d/tests", user, repo, id)
+	if ref != "" {
+		link += "?ref=" + url.QueryEscape(ref)
+	}
+	_, resp, err := c.getResponse("POST", link, nil, nil)
+	return resp, err
+}
@@ -0,0 +1,389 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// HookConfig is the typed config of a hook type, see NewCreateHookOption
+type HookConfig interface {
+	// HookType returns the type of the hook
+	HookType() HookType
+	// Validate checks the required keys are set
+	Validate() error
+	// Config returns the config as expected by CreateHookOption and EditHookOption
+	Config() map[string]string
+}
+
+// HookContentType is the content type of the payloads of gitea and gogs hooks
+type HookContentType string
+
+const (
+	// HookContentTypeJSON sends payloads as application/json
+	HookContentTypeJSON HookContentType = "json"
+	// HookContentTypeForm sends payloads as application/x-www-form-urlencoded
+	HookContentTypeForm HookContentType = "form"
+)
+
+// hookEventTypes are the events the API accepts for hooks
+var hookEventTypes = []HookEventType{
+	HookEventCreate, HookEventDelete, HookEventFork, HookEventPush,
+	HookEventIssues, HookEventIssuesOnly, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone,
+	HookEventIssueComment, HookEventPullRequest, HookEventPullRequestOnly, HookEventPullRequestAssign,
+	HookEventPullRequestLabel, HookEventPullRequestMilestone, HookEventPullRequestComment,
+	HookEventPullRequestReview, HookEventPullRequestSync, HookEventPullRequestReviewRequest,
+	HookEventWiki, HookEventRepository, HookEventRelease, HookEventPackage, HookEventStatus,
+	HookEventWorkflowRun, HookEventWorkflowJob,
+}
+
+// HookEventTypes returns all events a hook can be subscribed to, see WebhookEventTypes
+// for the events delivered to hooks
+func HookEventTypes() []HookEventType {
+	return append([]HookEventType(nil), hookEventTypes...)
+}
+
+// IsValid reports whether a hook can be subscribed to the event
+func (t HookEventType) IsValid() bool {
+	for _, e := range hookEventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCreateHookOption creates an active CreateHookOption for a typed config,
+// no events subscribe to push events only
+func NewCreateHookOption(cfg HookConfig, events ...HookEventType) (CreateHookOption, error) {
+	if err := cfg.Validate(); err != nil {
+		return CreateHookOption{}, err
+	}
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		names = append(names, string(e))
+	}
+	opt := CreateHookOption{
+		Type:   cfg.HookType(),
+		Config: cfg.Config(),
+		Events: names,
+		Active: true,
+	}
+	if a, ok := cfg.(interface{ authorizationHeader() string }); ok {
+		opt.AuthorizationHeader = a.authorizationHeader()
+	}
+	return opt, opt.ValidateStrict()
+}
+
+// validateHookOptions validates the options shared by CreateHookOption and EditHookOption
+func validateHookOptions(events []string, branchFilter, authorizationHeader string) error {
+	for _, e := range events {
+		if !HookEventType(e).IsValid() {
+			return fmt.Errorf("invalid hook event %q", e)
+		}
+	}
+	if strings.Count(branchFilter, "{") != strings.Count(branchFilter, "}") ||
+		strings.Count(branchFilter, "[") != strings.Count(branchFilter, "]") {
+		return fmt.Errorf("invalid branch filter %q", branchFilter)
+	}
+	if strings.ContainsAny(authorizationHeader, "\r\n") {
+		return fmt.Errorf("authorization header must be a single line")
+	}
+	return nil
+}
+
+// validateHookURL checks a hook URL is an absolute http(s) URL
+func validateHookURL(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s is empty", name)
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s %q is no http(s) URL", name, value)
+	}
+	return nil
+}
+
+// setIfNotEmpty sets config[key] to value unless value is empty
+func setIfNotEmpty(config map[string]string, key, value string) {
+	if value != "" {
+		config[key] = value
+	}
+}
+
+// GiteaHookConfig is the config of HookTypeGitea hooks
+type GiteaHookConfig struct {
+	URL string
+	// ContentType defaults to HookContentTypeJSON
+	ContentType HookContentType
+	// Secret is used to sign the payloads, see VerifyWebhookSignature
+	Secret string
+	// HTTPMethod defaults to POST
+	HTTPMethod string
+}
+
+// HookType implements HookConfig
+func (GiteaHookConfig) HookType() HookType { return HookTypeGitea }
+
+// Validate implements HookConfig
+func (c GiteaHookConfig) Validate() error {
+	if err := validateHookURL("url", c.URL); err != nil {
+		return err
+	}
+	if c.ContentType != "" && c.ContentType != HookContentTypeJSON && c.ContentType != HookContentTypeForm {
+		return fmt.Errorf("invalid content type %q", c.ContentType)
+	}
+	switch strings.ToUpper(c.HTTPMethod) {
+	case "", "POST", "GET", "PUT":
+		return nil
+	}
+	return fmt.Errorf("invalid http method %q", c.HTTPMethod)
+}
+
+// Config implements HookConfig
+func (c GiteaHookConfig) Config() map[string]string {
+	config := map[string]string{"url": c.URL, "content_type": string(HookContentTypeJSON)}
+	setIfNotEmpty(config, "content_type", string(c.ContentType))
+	setIfNotEmpty(config, "secret", c.Secret)
+	setIfNotEmpty(config, "http_method", strings.ToLower(c.HTTPMethod))
+	return config
+}
+
+// GogsHookConfig is the config of HookTypeGogs hooks
+type GogsHookConfig struct {
+	URL string
+	// ContentType defaults to HookContentTypeJSON
+	ContentType HookContentType
+	Secret      string
+}
+
+// HookType implements HookConfig
+func (GogsHookConfig) HookType() HookType { return HookTypeGogs }
+
+// Validate implements HookConfig
+func (c GogsHookConfig) Validate() error {
+	return GiteaHookConfig{URL: c.URL, ContentType: c.ContentType}.Validate()
+}
+
+// Config implements HookConfig
+func (c GogsHookConfig) Config() map[string]string {
+	return GiteaHookConfig{URL: c.URL, ContentType: c.ContentType, Secret: c.Secret}.Config()
+}
+
+// SlackHookConfig is the config of HookTypeSlack hooks
+type SlackHookConfig struct {
+	URL string
+	// Channel is required and starts with # for channels or @ for users
+	Channel  string
+	Username string
+	IconURL  string
+	Color    string
+}
+
+// HookType implements HookConfig
+func (SlackHookConfig) HookType() HookType { return HookTypeSlack }
+
+// Validate implements HookConfig
+func (c SlackHookConfig) Validate() error {
+	if err := validateHookURL("url", c.URL); err != nil {
+		return err
+	}
+	channel := strings.TrimLeft(c.Channel, "#@")
+	if channel == "" || strings.ContainsAny(channel, " \t") {
+		return fmt.Errorf("invalid slack channel %q", c.Channel)
+	}
+	if c.IconURL != "" {
+		return validateHookURL("icon url", c.IconURL)
+	}
+	return nil
+}
+
+// Config implements HookConfig
+func (c SlackHookConfig) Config() map[string]string {
+	config := map[string]string{"url": c.URL, "content_type": string(HookContentTypeJSON), "channel": c.Channel}
+	setIfNotEmpty(config, "username", c.Username)
+	setIfNotEmpty(config, "icon_url", c.IconURL)
+	setIfNotEmpty(config, "color", c.Color)
+	return config
+}
+
+// DiscordHookConfig is the config of HookTypeDiscord hooks
+type DiscordHookConfig struct {
+	URL      string
+	Username string
+	IconURL  string
+}
+
+// HookType implements HookConfig
+func (DiscordHookConfig) HookType() HookType { return HookTypeDiscord }
+
+// Validate implements HookConfig
+func (c DiscordHookConfig) Validate() error {
+	if err := validateHookURL("url", c.URL); err != nil {
+		return err
+	}
+	if c.IconURL != "" {
+		return validateHookURL("icon url", c.IconURL)
+	}
+	return nil
+}
+
+// Config implements HookConfig
+func (c DiscordHookConfig) Config() map[string]string {
+	config := map[string]string{"url": c.URL, "content_type": string(HookContentTypeJSON)}
+	setIfNotEmpty(config, "username", c.Username)
+	setIfNotEmpty(config, "icon_url", c.IconURL)
+	return config
+}
+
+// URLHookConfig is the config of hook types only needing the URL of the service:
+// HookTypeDingtalk, HookTypeMsteams, HookTypeFeishu and HookTypeWechatwork
+type URLHookConfig struct {
+	Type HookType
+	URL  string
+}
+
+// HookType implements HookConfig
+func (c URLHookConfig) HookType() HookType { return c.Type }
+
+// Validate implements HookConfig
+func (c URLHookConfig) Validate() error {
+	switch c.Type {
+	case HookTypeDingtalk, HookTypeMsteams, HookTypeFeishu, HookTypeWechatwork:
+		return validateHookURL("url", c.URL)
+	}
+	return fmt.Errorf("hook type %q needs more than an url", c.Type)
+}
+
+// Config implements HookConfig
+func (c URLHookConfig) Config() map[string]string {
+	return map[string]string{"url": c.URL, "content_type": string(HookContentTypeJSON)}
+}
+
+// TelegramHookConfig is the config of HookTypeTelegram hooks
+type TelegramHookConfig struct {
+	BotToken string
+	ChatID   string
+	// ThreadID is the optional topic of the chat
+	ThreadID string
+}
+
+// HookType implements HookConfig
+func (TelegramHookConfig) HookType() HookType { return HookTypeTelegram }
+
+// Validate implements HookConfig
+func (c TelegramHookConfig) Validate() error {
+	if c.BotToken == "" {
+		return fmt.Errorf("bot token is empty")
+	}
+	if c.ChatID == "" {
+		return fmt.Errorf("chat id is empty")
+	}
+	return nil
+}
+
+// Config implements HookConfig
+func (c TelegramHookConfig) Config() map[string]string {
+	query := url.Values{"chat_id": {c.ChatID}}
+	if c.ThreadID != "" {
+		query.Set("message_thread_id", c.ThreadID)
+	}
+	return map[string]string{
+		"url":          fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?%s", url.PathEscape(c.BotToken), query.Encode()),
+		"content_type": string(HookContentTypeJSON),
+	}
+}
+
+// MatrixMessageType is the type of the messages sent by matrix hooks
+type MatrixMessageType string
+
+const (
+	// MatrixMessageTypeText sends m.text messages
+	MatrixMessageTypeText MatrixMessageType = "m.text"
+	// MatrixMessageTypeNotice sends m.notice messages
+	MatrixMessageTypeNotice MatrixMessageType = "m.notice"
+)
+
+// MatrixHookConfig is the config of HookTypeMatrix hooks
+type MatrixHookConfig struct {
+	HomeserverURL string
+	RoomID        string
+	// MessageType defaults to MatrixMessageTypeNotice
+	MessageType MatrixMessageType
+	// AccessToken is sent as bearer token in the authorization header
+	AccessToken string
+}
+
+// HookType implements HookConfig
+func (MatrixHookConfig) HookType() HookType { return HookTypeMatrix }
+
+// Validate implements HookConfig
+func (c MatrixHookConfig) Validate() error {
+	if err := validateHookURL("homeserver url", c.HomeserverURL); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(c.RoomID, "!") || !strings.Contains(c.RoomID, ":") {
+		return fmt.Errorf("invalid matrix room id %q", c.RoomID)
+	}
+	if c.MessageType != "" && c.MessageType != MatrixMessageTypeText && c.MessageType != MatrixMessageTypeNotice {
+		return fmt.Errorf("invalid matrix message type %q", c.MessageType)
+	}
+	if c.AccessToken == "" {
+		return fmt.Errorf("access token is empty")
+	}
+	return nil
+}
+
+// Config implements HookConfig
+func (c MatrixHookConfig) Config() map[string]string {
+	messageType := c.MessageType
+	if messageType == "" {
+		messageType = MatrixMessageTypeNotice
+	}
+	return map[string]string{
+		"url": fmt.Sprintf("%s/_matrix/client/r0/rooms/%s/send/m.room.message",
+			strings.TrimSuffix(c.HomeserverURL, "/"), url.PathEscape(c.RoomID)),
+		"content_type": string(HookContentTypeJSON),
+		"room_id":      c.RoomID,
+		"message_type": string(messageType),
+	}
+}
+
+func (c MatrixHookConfig) authorizationHeader() string {
+	return "Bearer " + c.AccessToken
+}
+
+// PackagistHookConfig is the config of HookTypePackagist hooks
+type PackagistHookConfig struct {
+	Username   string
+	APIToken   string
+	PackageURL string
+}
+
+// HookType implements HookConfig
+func (PackagistHookConfig) HookType() HookType { return HookTypePackagist }
+
+// Validate implements HookConfig
+func (c PackagistHookConfig) Validate() error {
+	if c.Username == "" {
+		return fmt.Errorf("username is empty")
+	}
+	if c.APIToken == "" {
+		return fmt.Errorf("api token is empty")
+	}
+	return validateHookURL("package url", c.PackageURL)
+}
+
+// Config implements HookConfig
+func (c PackagistHookConfig) Config() map[string]string {
+	query := url.Values{"username": {c.Username}, "apiToken": {c.APIToken}}
+	return map[string]string{
+		"url":          "https://packagist.org/api/update-package?" + query.Encode(),
+		"content_type": string(HookContentTypeJSON),
+		"package_url":  c.PackageURL,
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookConfig(t *testing.T) {
+	opt, err := NewCreateHookOption(GiteaHookConfig{URL: "https://ci.example.com/hook", Secret: "s3cr3t", HTTPMethod: "POST"},
+		HookEventPush, HookEventPullRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, HookTypeGitea, opt.Type)
+	assert.True(t, opt.Active)
+	assert.Equal(t, []string{"push", "pull_request"}, opt.Events)
+	assert.Equal(t, map[string]string{
+		"url":          "https://ci.example.com/hook",
+		"content_type": "json",
+		"secret":       "s3cr3t",
+		"http_method":  "post",
+	}, opt.Config)
+
+	_, err = NewCreateHookOption(GiteaHookConfig{URL: "ci.example.com"})
+	assert.Error(t, err)
+	_, err = NewCreateHookOption(GiteaHookConfig{URL: "https://ci.example.com", ContentType: "xml"})
+	assert.Error(t, err)
+	_, err = NewCreateHookOption(GiteaHookConfig{URL: "https://ci.example.com"}, "pushed")
+	assert.EqualError(t, err, `invalid hook event "pushed"`)
+
+	_, err = NewCreateHookOption(SlackHookConfig{URL: "https://hooks.slack.com/x"})
+	assert.Error(t, err, "slack hooks need a channel")
+	opt, err = NewCreateHookOption(SlackHookConfig{URL: "https://hooks.slack.com/x", Channel: "#dev", Color: "good"})
+	assert.NoError(t, err)
+	assert.Equal(t, "#dev", opt.Config["channel"])
+	assert.Equal(t, "good", opt.Config["color"])
+
+	opt, err = NewCreateHookOption(MatrixHookConfig{HomeserverURL: "https://matrix.org/", RoomID: "!abc:matrix.org", AccessToken: "t0k3n"})
+	assert.NoError(t, err)
+	assert.Equal(t, HookTypeMatrix, opt.Type)
+	assert.Equal(t, "https://matrix.org/_matrix/client/r0/rooms/%21abc:matrix.org/send/m.room.message", opt.Config["url"])
+	assert.Equal(t, "m.notice", opt.Config["message_type"])
+	assert.Equal(t, "Bearer t0k3n", opt.AuthorizationHeader)
+
+	opt, err = NewCreateHookOption(TelegramHookConfig{BotToken: "123:abc", ChatID: "-42", ThreadID: "7"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.telegram.org/bot123:abc/sendMessage?chat_id=-42&message_thread_id=7", opt.Config["url"])
+
+	opt, err = NewCreateHookOption(PackagistHookConfig{Username: "alice", APIToken: "t0k3n", PackageURL: "https://packagist.org/packages/alice/demo"})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://packagist.org/api/update-package?apiToken=t0k3n&username=alice", opt.Config["url"])
+
+	_, err = NewCreateHookOption(URLHookConfig{Type: HookTypeWechatwork, URL: "https://qyapi.weixin.qq.com/x"})
+	assert.NoError(t, err)
+	_, err = NewCreateHookOption(URLHookConfig{Type: HookTypeSlack, URL: "https://hooks.slack.com/x"})
+	assert.Error(t, err)
+
+	assert.Error(t, CreateHookOption{Type: HookTypeGitea, BranchFilter: "{main,dev"}.ValidateStrict())
+	assert.NoError(t, CreateHookOption{Type: HookTypeGitea, Events: []string{"future_event"}}.Validate())
+	assert.Error(t, CreateHookOption{Type: HookTypeGitea, Events: []string{"future_event"}}.ValidateStrict())
+	assert.Error(t, EditHookOption{AuthorizationHeader: "Bearer x\r\nX-Evil: 1"}.ValidateStrict())
+	assert.NoError(t, EditHookOption{BranchFilter: "{main,release/*}"}.ValidateStrict())
+	assert.Len(t, HookEventTypes(), 26)
+	assert.True(t, HookEventIssuesOnly.IsValid())
+	assert.True(t, HookEventPullRequestReview.IsValid())
+	assert.False(t, HookEventPullRequestReviewApproved.IsValid(), "delivered, but not subscribable")
+	assert.Len(t, WebhookEventTypes(), 26)
+}
+
+func TestCreateHookSubscriptionEvents(t *testing.T) {
+	var got CreateHookOption
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/api/v1/repos/alice/demo/hooks")
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(Hook{ID: 1, Type: string(got.Type), Events: got.Events, Active: got.Active})
+	}))
+	defer server.Close()
+	c, err := NewClient(server.URL, SetGiteaVersion(""))
+	assert.NoError(t, err)
+
+	opt, err := NewCreateHookOption(GiteaHookConfig{URL: "https://ci.example.com/hook"}, HookEventIssuesOnly, HookEventPullRequestReview)
+	assert.NoError(t, err)
+	hook, _, err := c.CreateRepoHook("alice", "demo", opt)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"issues_only", "pull_request_review"}, got.Events)
+	assert.Equal(t, int64(1), hook.ID)
+	assert.Equal(t, []string{"issues", "pull_request_review_approved", "pull_request_review_comment", "pull_request_review_rejected"},
+		expandHookEvents(got.Events))
+
+	// the events are checked by the typed config path only, the server decides for the others
+	spec := CreateHookOption{Type: HookTypeGitea, Config: opt.Config, Events: []string{"future_event"}}
+	assert.EqualError(t, spec.ValidateStrict(), `invalid hook event "future_event"`)
+	_, _, err = c.CreateRepoHook("alice", "demo", spec)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"future_event"}, got.Events)
+	_, err = c.EditRepoHook("alice", "demo", 1, EditHookOption{Events: []string{"future_event"}})
+	assert.NoError(t, err)
+}
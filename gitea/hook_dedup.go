@@ -0,0 +1,204 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultDeliveryTTL is how long deliveries are remembered by default
+const defaultDeliveryTTL = 24 * time.Hour
+
+// WebhookDeliveryStore records the keys of handled webhook deliveries.
+// Implementations must be safe for concurrent use, stores shared by several
+// instances of a service (e.g. backed by Redis) must implement Add atomically.
+type WebhookDeliveryStore interface {
+	// Add records key for ttl, it returns false if key is already recorded and not expired
+	Add(ctx context.Context, key string, ttl time.Duration) (bool, error)
+	// Remove forgets key, so the delivery is accepted again
+	Remove(ctx context.Context, key string) error
+}
+
+// MemoryDeliveryStore is an in-memory WebhookDeliveryStore
+type MemoryDeliveryStore struct {
+	mutex     sync.Mutex
+	expires   map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryDeliveryStore creates a MemoryDeliveryStore
+func NewMemoryDeliveryStore() *MemoryDeliveryStore {
+	return &MemoryDeliveryStore{expires: make(map[string]time.Time), now: time.Now}
+}
+
+// Add records key for ttl, it returns false if key is already recorded and not expired
+func (m *MemoryDeliveryStore) Add(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	now := m.now()
+	// drop expired keys from time to time, so the store does not grow forever
+	if now.Sub(m.lastSweep) >= ttl {
+		for k, expires := range m.expires {
+			if !now.Before(expires) {
+				delete(m.expires, k)
+			}
+		}
+		m.lastSweep = now
+	}
+	if expires, ok := m.expires[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+	m.expires[key] = now.Add(ttl)
+	return true, nil
+}
+
+// Remove forgets key
+func (m *MemoryDeliveryStore) Remove(_ context.Context, key string) error {
+	m.mutex.Lock()
+	delete(m.expires, key)
+	m.mutex.Unlock()
+	return nil
+}
+
+// WebhookDedupOptions configures a WebhookDeduplicator
+type WebhookDedupOptions struct {
+	// Store records the handled deliveries, defaults to a MemoryDeliveryStore
+	Store WebhookDeliveryStore
+	// TTL is how long deliveries are remembered, defaults to 24 hours.
+	// Deliveries replayed after the TTL are accepted again.
+	TTL time.Duration
+	// RejectDuplicates answers duplicates with 409 Conflict, by default they are
+	// answered with 200 OK so Gitea regards them as delivered
+	RejectDuplicates bool
+	// RequireDeliveryID rejects webhooks without X-Gitea-Delivery header with 400 Bad Request,
+	// by default they are passed on
+	RequireDeliveryID bool
+}
+
+// WebhookDedupStats contains statistics about the deduplication of webhooks
+type WebhookDedupStats struct {
+	// Accepted is the number of deliveries passed on to the handler
+	Accepted int64
+	// Duplicates is the number of deliveries recognized as duplicates
+	Duplicates int64
+	// MissingID is the number of webhooks without X-Gitea-Delivery header
+	MissingID int64
+	// StoreErrors is the number of deliveries rejected because the store failed
+	StoreErrors int64
+}
+
+// WebhookDeduplicator drops duplicate webhook deliveries, like redeliveries of Gitea
+// or replays of captured requests, based on the SHA-256 hash of their body.
+// The X-Gitea-Delivery header is not used for this, as it is not covered by the
+// signature and a replayed request can carry any delivery ID. Gitea payloads contain
+// the IDs and timestamps of what happened, so distinct events do not share a body.
+// It must be placed after the signature verification, so unsigned requests cannot
+// mark deliveries as seen:
+//
+//	dedup := gitea.NewWebhookDeduplicator(gitea.WebhookDedupOptions{})
+//	http.Handle("/hook", gitea.VerifyWebhookSignatureMiddleware(secret)(dedup.Middleware(router)))
+//
+// If the handler answers with a status code of 500 or above, the delivery is forgotten
+// so a redelivery is handled again.
+type WebhookDeduplicator struct {
+	opt WebhookDedupOptions
+
+	accepted    atomic.Int64
+	duplicates  atomic.Int64
+	missingID   atomic.Int64
+	storeErrors atomic.Int64
+}
+
+// NewWebhookDeduplicator creates a WebhookDeduplicator
+func NewWebhookDeduplicator(opt WebhookDedupOptions) *WebhookDeduplicator {
+	if opt.Store == nil {
+		opt.Store = NewMemoryDeliveryStore()
+	}
+	if opt.TTL <= 0 {
+		opt.TTL = defaultDeliveryTTL
+	}
+	return &WebhookDeduplicator{opt: opt}
+}
+
+// Stats returns the statistics of the deduplicator
+func (d *WebhookDeduplicator) Stats() WebhookDedupStats {
+	return WebhookDedupStats{
+		Accepted:    d.accepted.Load(),
+		Duplicates:  d.duplicates.Load(),
+		MissingID:   d.missingID.Load(),
+		StoreErrors: d.storeErrors.Load(),
+	}
+}
+
+// Middleware wraps next, passing on only the first delivery of every payload
+func (d *WebhookDeduplicator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Gitea-Delivery") == "" {
+			d.missingID.Add(1)
+			if d.opt.RequireDeliveryID {
+				http.Error(w, "no delivery id found", http.StatusBadRequest)
+				return
+			}
+		}
+
+		var b bytes.Buffer
+		if _, err := io.Copy(&b, r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		r.Body = io.NopCloser(&b)
+		sum := sha256.Sum256(b.Bytes())
+		key := hex.EncodeToString(sum[:])
+
+		added, err := d.opt.Store.Add(r.Context(), key, d.opt.TTL)
+		if err != nil {
+			d.storeErrors.Add(1)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if !added {
+			d.duplicates.Add(1)
+			if d.opt.RejectDuplicates {
+				http.Error(w, "duplicate delivery", http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		d.accepted.Add(1)
+		sw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+		if sw.status >= http.StatusInternalServerError {
+			// let Gitea redeliver it, the request context may be canceled by now
+			_ = d.opt.Store.Remove(context.WithoutCancel(r.Context()), key)
+		}
+	})
+}
+
+// statusRecorder records the status code written to a http.ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap allows http.ResponseController to reach the underlying http.ResponseWriter
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
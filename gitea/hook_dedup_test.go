@@ -0,0 +1,93 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingDeliveryStore struct{}
+
+func (failingDeliveryStore) Add(context.Context, string, time.Duration) (bool, error) {
+	return false, errors.New("store unavailable")
+}
+
+func (failingDeliveryStore) Remove(context.Context, string) error { return nil }
+
+func TestWebhookDeduplicator(t *testing.T) {
+	calls := 0
+	status := http.StatusNoContent
+	dedup := NewWebhookDeduplicator(WebhookDedupOptions{})
+	handler := VerifyWebhookSignaturesMiddleware("old", "new")(dedup.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		assert.NotEmpty(t, body, "the body is passed on")
+		w.WriteHeader(status)
+	})))
+	serve := func(delivery, body, secret string) int {
+		r := newWebhookRequest(HookEventPush, body, secret)
+		r.Header.Set("X-Gitea-Delivery", delivery)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve("a", `{"after":"1"}`, "old"))
+	assert.Equal(t, http.StatusNoContent, serve("b", `{"after":"2"}`, "new"))
+	assert.Equal(t, http.StatusUnauthorized, serve("c", `{"after":"3"}`, "other"))
+	assert.Equal(t, http.StatusOK, serve("a", `{"after":"1"}`, "new"), "duplicates are acknowledged")
+	assert.Equal(t, http.StatusOK, serve("replayed", `{"after":"1"}`, "old"), "replays with a new delivery id are duplicates")
+	assert.Equal(t, 2, calls)
+
+	// failed deliveries can be redelivered
+	status = http.StatusInternalServerError
+	assert.Equal(t, http.StatusInternalServerError, serve("d", `{"after":"4"}`, "new"))
+	status = http.StatusNoContent
+	assert.Equal(t, http.StatusNoContent, serve("e", `{"after":"4"}`, "new"))
+	assert.Equal(t, 4, calls)
+
+	assert.Equal(t, http.StatusNoContent, serve("", `{"after":"5"}`, "new"))
+	assert.Equal(t, http.StatusOK, serve("", `{"after":"5"}`, "new"))
+	assert.Equal(t, WebhookDedupStats{Accepted: 5, Duplicates: 3, MissingID: 2}, dedup.Stats())
+
+	strict := NewWebhookDeduplicator(WebhookDedupOptions{RejectDuplicates: true, RequireDeliveryID: true})
+	handler = strict.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	assert.Equal(t, http.StatusOK, serve("a", `{}`, ""))
+	assert.Equal(t, http.StatusConflict, serve("b", `{}`, ""))
+	assert.Equal(t, http.StatusBadRequest, serve("", `{"after":"1"}`, ""))
+
+	handler = NewWebhookDeduplicator(WebhookDedupOptions{Store: failingDeliveryStore{}}).Middleware(handler)
+	assert.Equal(t, http.StatusServiceUnavailable, serve("a", `{}`, ""))
+}
+
+func TestMemoryDeliveryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryDeliveryStore()
+	store.now = func() time.Time { return now }
+
+	added, err := store.Add(ctx, "a", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, added)
+	added, _ = store.Add(ctx, "a", time.Minute)
+	assert.False(t, added)
+
+	now = now.Add(time.Minute)
+	added, _ = store.Add(ctx, "a", time.Minute)
+	assert.True(t, added, "expired keys are accepted again")
+	assert.Len(t, store.expires, 1)
+
+	assert.NoError(t, store.Remove(ctx, "a"))
+	added, _ = store.Add(ctx, "a", time.Minute)
+	assert.True(t, added)
+}
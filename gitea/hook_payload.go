@@ -0,0 +1,460 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HookEventType is the type of a webhook event, as sent in the X-Gitea-Event-Type header,
+// or an event a hook can be subscribed to
+type HookEventType string
+
+const (
+	// HookEventCreate is sent when a branch or tag was created
+	HookEventCreate HookEventType = "create"
+	// HookEventDelete is sent when a branch or tag was deleted
+	HookEventDelete HookEventType = "delete"
+	// HookEventFork is sent when a repository was forked
+	HookEventFork HookEventType = "fork"
+	// HookEventPush is sent when commits were pushed
+	HookEventPush HookEventType = "push"
+	// HookEventIssues is sent when an issue was opened, edited, closed or reopened
+	HookEventIssues HookEventType = "issues"
+	// HookEventIssueAssign is sent when an issue was assigned or unassigned
+	HookEventIssueAssign HookEventType = "issue_assign"
+	// HookEventIssueLabel is sent when the labels of an issue were changed
+	HookEventIssueLabel HookEventType = "issue_label"
+	// HookEventIssueMilestone is sent when the milestone of an issue was changed
+	HookEventIssueMilestone HookEventType = "issue_milestone"
+	// HookEventIssueComment is sent when a comment on an issue was created, edited or deleted
+	HookEventIssueComment HookEventType = "issue_comment"
+	// HookEventPullRequest is sent when a pull request was opened, edited, closed or reopened
+	HookEventPullRequest HookEventType = "pull_request"
+	// HookEventPullRequestAssign is sent when a pull request was assigned or unassigned
+	HookEventPullRequestAssign HookEventType = "pull_request_assign"
+	// HookEventPullRequestLabel is sent when the labels of a pull request were changed
+	HookEventPullRequestLabel HookEventType = "pull_request_label"
+	// HookEventPullRequestMilestone is sent when the milestone of a pull request was changed
+	HookEventPullRequestMilestone HookEventType = "pull_request_milestone"
+	// HookEventPullRequestComment is sent when a comment on a pull request was created, edited or deleted
+	HookEventPullRequestComment HookEventType = "pull_request_comment"
+	// HookEventPullRequestReviewApproved is sent when a pull request was approved
+	HookEventPullRequestReviewApproved HookEventType = "pull_request_review_approved"
+	// HookEventPullRequestReviewRejected is sent when changes to a pull request were requested
+	HookEventPullRequestReviewRejected HookEventType = "pull_request_review_rejected"
+	// HookEventPullRequestReviewComment is sent when a pull request was reviewed with a comment
+	HookEventPullRequestReviewComment HookEventType = "pull_request_review_comment"
+	// HookEventPullRequestSync is sent when the head branch of a pull request was updated
+	HookEventPullRequestSync HookEventType = "pull_request_sync"
+	// HookEventPullRequestReviewRequest is sent when a review of a pull request was requested or the request removed
+	HookEventPullRequestReviewRequest HookEventType = "pull_request_review_request"
+	// HookEventWiki is sent when a wiki page was created, edited or deleted
+	HookEventWiki HookEventType = "wiki"
+	// HookEventRepository is sent when a repository was created or deleted
+	HookEventRepository HookEventType = "repository"
+	// HookEventRelease is sent when a release was published, updated or deleted
+	HookEventRelease HookEventType = "release"
+	// HookEventPackage is sent when a package was created or deleted
+	HookEventPackage HookEventType = "package"
+	// HookEventStatus is sent when a commit status was set
+	HookEventStatus HookEventType = "status"
+	// HookEventWorkflowRun is sent when an actions workflow run was queued, started or completed
+	HookEventWorkflowRun HookEventType = "workflow_run"
+	// HookEventWorkflowJob is sent when an actions job was queued, started or completed
+	HookEventWorkflowJob HookEventType = "workflow_job"
+
+	// events hooks can be subscribed to, which are not delivered under this name
+
+	// HookEventIssuesOnly subscribes to issue events without the assign, label, milestone
+	// and comment events, which HookEventIssues subscribes to as well
+	HookEventIssuesOnly HookEventType = "issues_only"
+	// HookEventPullRequestOnly subscribes to pull request events without the variants
+	// HookEventPullRequest subscribes to as well
+	HookEventPullRequestOnly HookEventType = "pull_request_only"
+	// HookEventPullRequestReview subscribes to the approved, rejected and comment review events
+	HookEventPullRequestReview HookEventType = "pull_request_review"
+)
+
+// Event returns the event as sent in the X-Gitea-Event header,
+// which groups the variants of issue and pull request events
+func (t HookEventType) Event() string {
+	switch t {
+	case HookEventIssues, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone:
+		return "issues"
+	case HookEventPullRequest, HookEventPullRequestAssign, HookEventPullRequestLabel, HookEventPullRequestMilestone,
+		HookEventPullRequestSync, HookEventPullRequestReviewRequest:
+		return "pull_request"
+	case HookEventIssueComment, HookEventPullRequestComment:
+		return "issue_comment"
+	case HookEventPullRequestReviewApproved:
+		return "pull_request_approved"
+	case HookEventPullRequestReviewRejected:
+		return "pull_request_rejected"
+	case HookEventPullRequestReviewComment:
+		return "pull_request_comment"
+	}
+	return string(t)
+}
+
+// webhookEventTypes are the events delivered to hooks
+var webhookEventTypes = []HookEventType{
+	HookEventCreate, HookEventDelete, HookEventFork, HookEventPush,
+	HookEventIssues, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone, HookEventIssueComment,
+	HookEventPullRequest, HookEventPullRequestAssign, HookEventPullRequestLabel, HookEventPullRequestMilestone,
+	HookEventPullRequestComment, HookEventPullRequestReviewApproved, HookEventPullRequestReviewRejected,
+	HookEventPullRequestReviewComment, HookEventPullRequestSync, HookEventPullRequestReviewRequest,
+	HookEventWiki, HookEventRepository, HookEventRelease, HookEventPackage, HookEventStatus,
+	HookEventWorkflowRun, HookEventWorkflowJob,
+}
+
+// WebhookEventTypes returns all events delivered to hooks, as sent in the X-Gitea-Event-Type header
+func WebhookEventTypes() []HookEventType {
+	return append([]HookEventType(nil), webhookEventTypes...)
+}
+
+// ErrUnknownWebhookEvent is returned by ParseWebhook for events without a payload type
+var ErrUnknownWebhookEvent = errors.New("unknown webhook event")
+
+// PusherType define the type to push
+type PusherType string
+
+// PusherTypeUser pusher type user
+const PusherTypeUser PusherType = "user"
+
+// CreatePayload represents a payload information of create event.
+type CreatePayload struct {
+	Sha     string      `json:"sha"`
+	Ref     string      `json:"ref"`
+	RefType string      `json:"ref_type"`
+	Repo    *Repository `json:"repository"`
+	Sender  *User       `json:"sender"`
+}
+
+// DeletePayload represents delete payload
+type DeletePayload struct {
+	Ref        string      `json:"ref"`
+	RefType    string      `json:"ref_type"`
+	PusherType PusherType  `json:"pusher_type"`
+	Repo       *Repository `json:"repository"`
+	Sender     *User       `json:"sender"`
+}
+
+// ForkPayload represents fork payload
+type ForkPayload struct {
+	Forkee *Repository `json:"forkee"`
+	Repo   *Repository `json:"repository"`
+	Sender *User       `json:"sender"`
+}
+
+// PushPayload represents a payload information of push event.
+type PushPayload struct {
+	Ref          string           `json:"ref"`
+	Before       string           `json:"before"`
+	After        string           `json:"after"`
+	CompareURL   string           `json:"compare_url"`
+	Commits      []*PayloadCommit `json:"commits"`
+	TotalCommits int              `json:"total_commits"`
+	HeadCommit   *PayloadCommit   `json:"head_commit"`
+	Repo         *Repository      `json:"repository"`
+	Pusher       *User            `json:"pusher"`
+	Sender       *User            `json:"sender"`
+}
+
+// HookIssueAction represents the action of an issue or pull request event
+type HookIssueAction string
+
+const (
+	// HookIssueOpened is sent when an issue or pull request was opened
+	HookIssueOpened HookIssueAction = "opened"
+	// HookIssueClosed is sent when an issue or pull request was closed, merged pull requests are closed too
+	HookIssueClosed HookIssueAction = "closed"
+	// HookIssueReOpened is sent when a closed issue or pull request was reopened
+	HookIssueReOpened HookIssueAction = "reopened"
+	// HookIssueEdited is sent when the title or body was edited, see the Changes of the payload
+	HookIssueEdited HookIssueAction = "edited"
+	// HookIssueDeleted is sent when an issue was deleted
+	HookIssueDeleted HookIssueAction = "deleted"
+	// HookIssueAssigned is sent when a user was assigned
+	HookIssueAssigned HookIssueAction = "assigned"
+	// HookIssueUnassigned is sent when a user was unassigned
+	HookIssueUnassigned HookIssueAction = "unassigned"
+	// HookIssueLabelUpdated is sent when the labels were changed
+	HookIssueLabelUpdated HookIssueAction = "label_updated"
+	// HookIssueLabelCleared is sent when all labels were removed
+	HookIssueLabelCleared HookIssueAction = "label_cleared"
+	// HookIssueSynchronized is sent when commits were pushed to the head branch of a pull request
+	HookIssueSynchronized HookIssueAction = "synchronized"
+	// HookIssueMilestoned is sent when a milestone was set
+	HookIssueMilestoned HookIssueAction = "milestoned"
+	// HookIssueDemilestoned is sent when the milestone was removed
+	HookIssueDemilestoned HookIssueAction = "demilestoned"
+	// HookIssueReviewed is sent when a pull request was reviewed, see the Review of the payload
+	HookIssueReviewed HookIssueAction = "reviewed"
+	// HookIssueReviewRequested is sent when a review was requested from the RequestedReviewer of the payload
+	HookIssueReviewRequested HookIssueAction = "review_requested"
+	// HookIssueReviewRequestRemoved is sent when a review request was removed
+	HookIssueReviewRequestRemoved HookIssueAction = "review_request_removed"
+)
+
+// ChangesFromPayload represents the previous value of a changed field
+type ChangesFromPayload struct {
+	From string `json:"from"`
+}
+
+// ChangesPayload represents the payload information of issue change
+type ChangesPayload struct {
+	Title *ChangesFromPayload `json:"title,omitempty"`
+	Body  *ChangesFromPayload `json:"body,omitempty"`
+	Ref   *ChangesFromPayload `json:"ref,omitempty"`
+}
+
+// IssuePayload represents the payload information that is sent along with an issue event,
+// including the assign, label and milestone variants.
+type IssuePayload struct {
+	Action     HookIssueAction `json:"action"`
+	Index      int64           `json:"number"`
+	Changes    *ChangesPayload `json:"changes,omitempty"`
+	Issue      *Issue          `json:"issue"`
+	Repository *Repository     `json:"repository"`
+	Sender     *User           `json:"sender"`
+	CommitID   string          `json:"commit_id"`
+}
+
+// HookIssueCommentAction defines hook issue comment action
+type HookIssueCommentAction string
+
+const (
+	// HookIssueCommentCreated is sent when a comment was created
+	HookIssueCommentCreated HookIssueCommentAction = "created"
+	// HookIssueCommentEdited is sent when a comment was edited, see the Changes of the payload
+	HookIssueCommentEdited HookIssueCommentAction = "edited"
+	// HookIssueCommentDeleted is sent when a comment was deleted
+	HookIssueCommentDeleted HookIssueCommentAction = "deleted"
+)
+
+// IssueCommentPayload represents a payload information of issue comment event,
+// IsPull is set for comments on pull requests.
+type IssueCommentPayload struct {
+	Action      HookIssueCommentAction `json:"action"`
+	Issue       *Issue                 `json:"issue"`
+	PullRequest *PullRequest           `json:"pull_request,omitempty"`
+	Comment     *Comment               `json:"comment"`
+	Changes     *ChangesPayload        `json:"changes,omitempty"`
+	Repository  *Repository            `json:"repository"`
+	Sender      *User                  `json:"sender"`
+	IsPull      bool                   `json:"is_pull"`
+}
+
+// ReviewPayload represents the review of a pull request review event
+type ReviewPayload struct {
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// PullRequestPayload represents a payload information of pull request event,
+// including the review, sync, label, assign and milestone variants.
+type PullRequestPayload struct {
+	Action            HookIssueAction `json:"action"`
+	Index             int64           `json:"number"`
+	Changes           *ChangesPayload `json:"changes,omitempty"`
+	PullRequest       *PullRequest    `json:"pull_request"`
+	RequestedReviewer *User           `json:"requested_reviewer"`
+	Repository        *Repository     `json:"repository"`
+	Sender            *User           `json:"sender"`
+	CommitID          string          `json:"commit_id"`
+	Review            *ReviewPayload  `json:"review"`
+}
+
+// HookReleaseAction defines hook release action type
+type HookReleaseAction string
+
+const (
+	// HookReleasePublished is sent when a release was published
+	HookReleasePublished HookReleaseAction = "published"
+	// HookReleaseUpdated is sent when a release was edited
+	HookReleaseUpdated HookReleaseAction = "updated"
+	// HookReleaseDeleted is sent when a release was deleted
+	HookReleaseDeleted HookReleaseAction = "deleted"
+)
+
+// ReleasePayload represents a payload information of release event.
+type ReleasePayload struct {
+	Action     HookReleaseAction `json:"action"`
+	Release    *Release          `json:"release"`
+	Repository *Repository       `json:"repository"`
+	Sender     *User             `json:"sender"`
+}
+
+// HookRepoAction an action that happens to a repo
+type HookRepoAction string
+
+const (
+	// HookRepoCreated is sent when a repository was created
+	HookRepoCreated HookRepoAction = "created"
+	// HookRepoDeleted is sent when a repository was deleted
+	HookRepoDeleted HookRepoAction = "deleted"
+)
+
+// RepositoryPayload payload for repository webhooks
+type RepositoryPayload struct {
+	Action       HookRepoAction `json:"action"`
+	Repository   *Repository    `json:"repository"`
+	Organization *User          `json:"organization"`
+	Sender       *User          `json:"sender"`
+}
+
+// HookWikiAction an action that happens to a wiki page
+type HookWikiAction string
+
+const (
+	// HookWikiCreated is sent when a wiki page was created
+	HookWikiCreated HookWikiAction = "created"
+	// HookWikiEdited is sent when a wiki page was edited
+	HookWikiEdited HookWikiAction = "edited"
+	// HookWikiDeleted is sent when a wiki page was deleted
+	HookWikiDeleted HookWikiAction = "deleted"
+)
+
+// WikiPayload payload for wiki webhooks
+type WikiPayload struct {
+	Action     HookWikiAction `json:"action"`
+	Repository *Repository    `json:"repository"`
+	Sender     *User          `json:"sender"`
+	Page       string         `json:"page"`
+	Comment    string         `json:"comment"`
+}
+
+// HookPackageAction an action that happens to a package
+type HookPackageAction string
+
+const (
+	// HookPackageCreated is sent when a package version was uploaded
+	HookPackageCreated HookPackageAction = "created"
+	// HookPackageDeleted is sent when a package version was deleted
+	HookPackageDeleted HookPackageAction = "deleted"
+)
+
+// PackagePayload represents a package payload
+type PackagePayload struct {
+	Action       HookPackageAction `json:"action"`
+	Repository   *Repository       `json:"repository"`
+	Package      *Package          `json:"package"`
+	Organization *User             `json:"organization"`
+	Sender       *User             `json:"sender"`
+}
+
+// CommitStatusPayload represents a payload information of commit status event
+type CommitStatusPayload struct {
+	ID          int64          `json:"id"`
+	SHA         string         `json:"sha"`
+	State       string         `json:"state"`
+	Context     string         `json:"context"`
+	Description string         `json:"description"`
+	TargetURL   string         `json:"target_url"`
+	Commit      *PayloadCommit `json:"commit"`
+	Repo        *Repository    `json:"repository"`
+	Sender      *User          `json:"sender"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   *time.Time     `json:"updated_at"`
+}
+
+// WorkflowRunPayload represents a payload information of workflow run event
+type WorkflowRunPayload struct {
+	Action       string             `json:"action"`
+	Workflow     *ActionWorkflow    `json:"workflow"`
+	WorkflowRun  *ActionWorkflowRun `json:"workflow_run"`
+	PullRequest  *PullRequest       `json:"pull_request,omitempty"`
+	Organization *Organization      `json:"organization,omitempty"`
+	Repo         *Repository        `json:"repository"`
+	Sender       *User              `json:"sender"`
+}
+
+// WorkflowJobPayload represents a payload information of workflow job event
+type WorkflowJobPayload struct {
+	Action       string             `json:"action"`
+	WorkflowJob  *ActionWorkflowJob `json:"workflow_job"`
+	PullRequest  *PullRequest       `json:"pull_request,omitempty"`
+	Organization *Organization      `json:"organization,omitempty"`
+	Repo         *Repository        `json:"repository"`
+	Sender       *User              `json:"sender"`
+}
+
+// WebhookEventType returns the type of the event of a webhook request, based on the
+// X-Gitea-Event-Type header and falling back to the X-Gitea-Event header of older Gitea versions
+func WebhookEventType(r *http.Request) HookEventType {
+	if t := r.Header.Get("X-Gitea-Event-Type"); t != "" {
+		return HookEventType(t)
+	}
+	switch event := r.Header.Get("X-Gitea-Event"); event {
+	case "pull_request_approved":
+		return HookEventPullRequestReviewApproved
+	case "pull_request_rejected":
+		return HookEventPullRequestReviewRejected
+	case "pull_request_comment":
+		return HookEventPullRequestReviewComment
+	default:
+		return HookEventType(event)
+	}
+}
+
+// ParseWebhook reads the body of a webhook request and decodes it into the payload type of
+// its event, e.g. *PushPayload for push events or *PullRequestPayload for all pull request events.
+// The signature is not verified, see VerifyWebhookSignature.
+func ParseWebhook(r *http.Request) (any, error) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseWebhookPayload(WebhookEventType(r), payload)
+}
+
+// ParseWebhookPayload decodes the payload of a webhook into the payload type of the event
+func ParseWebhookPayload(eventType HookEventType, payload []byte) (any, error) {
+	var v any
+	switch eventType {
+	case HookEventCreate:
+		v = new(CreatePayload)
+	case HookEventDelete:
+		v = new(DeletePayload)
+	case HookEventFork:
+		v = new(ForkPayload)
+	case HookEventPush:
+		v = new(PushPayload)
+	case HookEventIssues, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone:
+		v = new(IssuePayload)
+	case HookEventIssueComment, HookEventPullRequestComment:
+		v = new(IssueCommentPayload)
+	case HookEventPullRequest, HookEventPullRequestAssign, HookEventPullRequestLabel, HookEventPullRequestMilestone,
+		HookEventPullRequestSync, HookEventPullRequestReviewRequest,
+		HookEventPullRequestReviewApproved, HookEventPullRequestReviewRejected, HookEventPullRequestReviewComment:
+		v = new(PullRequestPayload)
+	case HookEventRelease:
+		v = new(ReleasePayload)
+	case HookEventRepository:
+		v = new(RepositoryPayload)
+	case HookEventWiki:
+		v = new(WikiPayload)
+	case HookEventPackage:
+		v = new(PackagePayload)
+	case HookEventStatus:
+		v = new(CommitStatusPayload)
+	case HookEventWorkflowRun:
+		v = new(WorkflowRunPayload)
+	case HookEventWorkflowJob:
+		v = new(WorkflowJobPayload)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownWebhookEvent, eventType)
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return nil, fmt.Errorf("decoding %s payload: %w", eventType, err)
+	}
+	return v, nil
+}
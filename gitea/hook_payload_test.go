@@ -0,0 +1,65 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWebhook(t *testing.T) {
+	newRequest := func(event, eventType, body string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+		r.Header.Set("X-Gitea-Event", event)
+		if eventType != "" {
+			r.Header.Set("X-Gitea-Event-Type", eventType)
+		}
+		return r
+	}
+
+	payload, err := ParseWebhook(newRequest("push", "push", `{"ref":"refs/heads/main","commits":[{"id":"abc"}],"repository":{"full_name":"alice/demo"}}`))
+	assert.NoError(t, err)
+	if push, ok := payload.(*PushPayload); assert.True(t, ok) {
+		assert.Equal(t, "refs/heads/main", push.Ref)
+		assert.Len(t, push.Commits, 1)
+		assert.Equal(t, "alice/demo", push.Repo.FullName)
+	}
+
+	payload, err = ParseWebhook(newRequest("pull_request", "pull_request_sync", `{"action":"synchronized","number":3,"pull_request":{"number":3}}`))
+	assert.NoError(t, err)
+	if pr, ok := payload.(*PullRequestPayload); assert.True(t, ok) {
+		assert.Equal(t, HookIssueSynchronized, pr.Action)
+		assert.EqualValues(t, 3, pr.PullRequest.Index)
+	}
+
+	// older Gitea versions only send X-Gitea-Event
+	r := newRequest("pull_request_approved", "", `{"action":"reviewed","review":{"type":"pull_request_review_approved","content":"lgtm"}}`)
+	assert.Equal(t, HookEventPullRequestReviewApproved, WebhookEventType(r))
+	payload, err = ParseWebhook(r)
+	assert.NoError(t, err)
+	if pr, ok := payload.(*PullRequestPayload); assert.True(t, ok) {
+		assert.Equal(t, "lgtm", pr.Review.Content)
+	}
+
+	payload, err = ParseWebhook(newRequest("issue_comment", "pull_request_comment", `{"action":"created","is_pull":true,"comment":{"body":"hi"}}`))
+	assert.NoError(t, err)
+	if comment, ok := payload.(*IssueCommentPayload); assert.True(t, ok) {
+		assert.True(t, comment.IsPull)
+		assert.Equal(t, "hi", comment.Comment.Body)
+	}
+
+	_, err = ParseWebhook(newRequest("unknown", "", `{}`))
+	assert.ErrorIs(t, err, ErrUnknownWebhookEvent)
+	_, err = ParseWebhook(newRequest("push", "", `{`))
+	assert.Error(t, err)
+
+	assert.Equal(t, "issues", HookEventIssueLabel.Event())
+	assert.Equal(t, "pull_request_rejected", HookEventPullRequestReviewRejected.Event())
+	assert.Equal(t, "issue_comment", HookEventPullRequestComment.Event())
+}
@@ -0,0 +1,312 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// HookTarget is a repository or, if Repo is empty, an organization whose hooks are reconciled
+type HookTarget struct {
+	Owner string
+	Repo  string
+}
+
+// String returns "owner/repo" for repositories and "owner" for organizations
+func (t HookTarget) String() string {
+	if t.Repo == "" {
+		return t.Owner
+	}
+	return t.Owner + "/" + t.Repo
+}
+
+// HookChangeAction is the action planned for a hook
+type HookChangeAction string
+
+const (
+	// HookChangeCreate creates a hook of a spec which has none
+	HookChangeCreate HookChangeAction = "create"
+	// HookChangeUpdate edits a hook which differs from its spec
+	HookChangeUpdate HookChangeAction = "update"
+	// HookChangeDelete deletes a hook without spec
+	HookChangeDelete HookChangeAction = "delete"
+	// HookChangeNone keeps a hook matching its spec
+	HookChangeNone HookChangeAction = "no-op"
+)
+
+// HookChange is a planned change of a hook
+type HookChange struct {
+	Action HookChangeAction
+	// Hook is the existing hook, nil for HookChangeCreate
+	Hook *Hook
+	// Spec is the desired hook, nil for HookChangeDelete
+	Spec *CreateHookOption
+	// Err is the error of applying the change
+	Err error
+}
+
+// String describes the change, e.g. "create gitea hook https://ci.example.com/hook"
+func (c HookChange) String() string {
+	hookType, hookURL := "", ""
+	if c.Spec != nil {
+		hookType, hookURL = string(c.Spec.Type), c.Spec.Config["url"]
+	} else if c.Hook != nil {
+		hookType, hookURL = c.Hook.Type, c.Hook.Config["url"]
+	}
+	s := fmt.Sprintf("%s %s hook %s", c.Action, hookType, hookURL)
+	if c.Hook != nil {
+		s += fmt.Sprintf(" (id %d)", c.Hook.ID)
+	}
+	if c.Err != nil {
+		s += ": " + c.Err.Error()
+	}
+	return s
+}
+
+// HookReport is the plan, and after applying it the outcome, of reconciling the hooks of a target
+type HookReport struct {
+	Target  HookTarget
+	Changes []*HookChange
+	// Err is the error of listing the hooks or of the first failed change
+	Err error
+}
+
+// Changed reports whether the plan contains changes other than HookChangeNone
+func (r *HookReport) Changed() bool {
+	for _, c := range r.Changes {
+		if c.Action != HookChangeNone {
+			return true
+		}
+	}
+	return false
+}
+
+// String lists the changes of the report, one per line
+func (r *HookReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:\n", r.Target)
+	if r.Err != nil && len(r.Changes) == 0 {
+		fmt.Fprintf(&b, "  error: %v\n", r.Err)
+	}
+	for _, c := range r.Changes {
+		fmt.Fprintf(&b, "  %s\n", c)
+	}
+	return b.String()
+}
+
+// ReconcileHooksOptions options for ReconcileHooks
+type ReconcileHooksOptions struct {
+	// DryRun only plans the changes
+	DryRun bool
+	// Prune deletes the hooks of a target matching no spec, by default they are kept
+	Prune bool
+	// Concurrency is the number of targets reconciled at once, defaults to 4
+	Concurrency int
+}
+
+// ReconcileHooks makes the hooks of every target match the specs.
+// Hooks are matched to specs by their type and config url. A matched hook is updated
+// if its events, branch filter, active state, authorization header or the config keys
+// returned by the API differ, the secret is not returned by the API and thus only set
+// when a hook is created. Servers not returning the authorization header update hooks
+// with an authorization header every time.
+// It returns a report per target in the order of the targets.
+func (c *Client) ReconcileHooks(ctx context.Context, targets []HookTarget, specs []CreateHookOption, opt ReconcileHooksOptions) []*HookReport {
+	concurrency := opt.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	client := c.WithContext(ctx)
+	reports := make([]*HookReport, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			reports[i] = &HookReport{Target: target, Err: ctx.Err()}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			report := client.PlanHooks(ctx, target, specs, opt.Prune)
+			if report.Err == nil && !opt.DryRun {
+				client.applyHookReport(ctx, report)
+			}
+			reports[i] = report
+		}()
+	}
+	wg.Wait()
+	return reports
+}
+
+// PlanHooks compares the hooks of a target with the specs, see ReconcileHooks
+func (c *Client) PlanHooks(ctx context.Context, target HookTarget, specs []CreateHookOption, prune bool) *HookReport {
+	c = c.WithContext(ctx)
+	report := &HookReport{Target: target}
+	for i := range specs {
+		if err := specs[i].Validate(); err != nil {
+			report.Err = err
+			return report
+		}
+	}
+	hooks, err := CollectAll(ctx, func(opt ListOptions) ([]*Hook, *Response, error) {
+		if target.Repo == "" {
+			return c.ListOrgHooks(target.Owner, ListHooksOptions{ListOptions: opt})
+		}
+		return c.ListRepoHooks(target.Owner, target.Repo, ListHooksOptions{ListOptions: opt})
+	}, 0)
+	if err != nil {
+		report.Err = err
+		return report
+	}
+
+	matched := make(map[int64]bool, len(hooks))
+	for i := range specs {
+		spec := &specs[i]
+		var hook *Hook
+		for _, h := range hooks {
+			if !matched[h.ID] && h.Type == string(spec.Type) && h.Config["url"] == spec.Config["url"] {
+				hook = h
+				break
+			}
+		}
+		switch {
+		case hook == nil:
+			report.Changes = append(report.Changes, &HookChange{Action: HookChangeCreate, Spec: spec})
+		case hookMatchesSpec(hook, spec):
+			matched[hook.ID] = true
+			report.Changes = append(report.Changes, &HookChange{Action: HookChangeNone, Hook: hook, Spec: spec})
+		default:
+			matched[hook.ID] = true
+			report.Changes = append(report.Changes, &HookChange{Action: HookChangeUpdate, Hook: hook, Spec: spec})
+		}
+	}
+	if prune {
+		for _, h := range hooks {
+			if !matched[h.ID] {
+				report.Changes = append(report.Changes, &HookChange{Action: HookChangeDelete, Hook: h})
+			}
+		}
+	}
+	return report
+}
+
+// applyHookReport applies the changes of a report, stopping at the first error
+func (c *Client) applyHookReport(ctx context.Context, report *HookReport) {
+	t := report.Target
+	for _, change := range report.Changes {
+		if err := ctx.Err(); err != nil {
+			report.Err = err
+			return
+		}
+		switch change.Action {
+		case HookChangeCreate:
+			if t.Repo == "" {
+				_, _, change.Err = c.CreateOrgHook(t.Owner, *change.Spec)
+			} else {
+				_, _, change.Err = c.CreateRepoHook(t.Owner, t.Repo, *change.Spec)
+			}
+		case HookChangeUpdate:
+			edit := EditHookOption{
+				Config:              change.Spec.Config,
+				Events:              change.Spec.Events,
+				BranchFilter:        change.Spec.BranchFilter,
+				Active:              &change.Spec.Active,
+				AuthorizationHeader: change.Spec.AuthorizationHeader,
+			}
+			if t.Repo == "" {
+				_, change.Err = c.EditOrgHook(t.Owner, change.Hook.ID, edit)
+			} else {
+				_, change.Err = c.EditRepoHook(t.Owner, t.Repo, change.Hook.ID, edit)
+			}
+		case HookChangeDelete:
+			if t.Repo == "" {
+				_, change.Err = c.DeleteOrgHook(t.Owner, change.Hook.ID)
+			} else {
+				_, change.Err = c.DeleteRepoHook(t.Owner, t.Repo, change.Hook.ID)
+			}
+		}
+		if change.Err != nil {
+			report.Err = change.Err
+			return
+		}
+	}
+}
+
+// hookMatchesSpec reports whether a hook needs no update to match its spec
+func hookMatchesSpec(hook *Hook, spec *CreateHookOption) bool {
+	if hook.Active != spec.Active || hook.BranchFilter != spec.BranchFilter ||
+		hook.AuthorizationHeader != spec.AuthorizationHeader {
+		return false
+	}
+	if !slices.Equal(expandHookEvents(hook.Events), expandHookEvents(spec.Events)) {
+		return false
+	}
+	for key, value := range spec.Config {
+		// keys like the secret are not returned by the API
+		if current, ok := hook.Config[key]; ok && current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// expandHookEvents returns the sorted events a hook subscribed to events receives,
+// Gitea subscribes hooks for "issues" and "pull_request" to all their variants
+// and for "pull_request_review" to all review events
+func expandHookEvents(events []string) []string {
+	if len(events) == 0 {
+		events = []string{string(HookEventPush)}
+	}
+	expanded := make([]string, 0, len(events))
+	for _, e := range events {
+		switch HookEventType(e) {
+		case HookEventIssues:
+			for _, v := range slices.Concat(issueEvents, []HookEventType{HookEventIssueComment}) {
+				expanded = append(expanded, string(v))
+			}
+		case HookEventPullRequest:
+			for _, v := range slices.Concat(pullRequestEvents, []HookEventType{HookEventPullRequestComment}) {
+				expanded = append(expanded, string(v))
+			}
+		case HookEventIssuesOnly:
+			expanded = append(expanded, string(HookEventIssues))
+		case HookEventPullRequestOnly:
+			expanded = append(expanded, string(HookEventPullRequest))
+		case HookEventPullRequestReview:
+			expanded = append(expanded, string(HookEventPullRequestReviewApproved),
+				string(HookEventPullRequestReviewRejected), string(HookEventPullRequestReviewComment))
+		default:
+			expanded = append(expanded, e)
+		}
+	}
+	slices.Sort(expanded)
+	return slices.Compact(expanded)
+}
+
+// OrgRepoHookTargets returns a HookTarget for every repository of the organizations
+func (c *Client) OrgRepoHookTargets(ctx context.Context, orgs ...string) ([]HookTarget, error) {
+	var targets []HookTarget
+	c = c.WithContext(ctx)
+	for _, org := range orgs {
+		repos, err := c.listAllOrgRepos(ctx, org)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repos {
+			targets = append(targets, HookTarget{Owner: org, Repo: repo.Name})
+		}
+	}
+	return targets, nil
+}
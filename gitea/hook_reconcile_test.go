@@ -0,0 +1,100 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea_test
+
+import (
+	"context"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+	"code.gitea.io/sdk/gitea/giteatest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcileHooks(t *testing.T) {
+	srv := giteatest.NewServer()
+	defer srv.Close()
+	srv.SeedUser("alice")
+	srv.SeedOrg("alice", "acme")
+	for _, name := range []string{"api", "web", "docs"} {
+		srv.SeedRepo("acme", gitea.CreateRepoOption{Name: name})
+	}
+	ci := gitea.CreateHookOption{
+		Type:   gitea.HookTypeGitea,
+		Config: map[string]string{"url": "https://ci.example.com/hook", "content_type": "json", "secret": "s3cr3t"},
+		Events: []string{"push", "pull_request"},
+		Active: true,
+	}
+	chat := gitea.CreateHookOption{
+		Type:         gitea.HookTypeSlack,
+		Config:       map[string]string{"url": "https://hooks.slack.com/x", "channel": "#dev"},
+		Events:       []string{"release"},
+		BranchFilter: "main",
+		Active:       true,
+	}
+	// api is up to date, web has an outdated and an unknown hook, docs has none
+	srv.SeedHook("acme", "api", ci)
+	srv.SeedHook("acme", "api", chat)
+	srv.SeedHook("acme", "web", gitea.CreateHookOption{Type: ci.Type, Config: ci.Config, Events: []string{"push"}, Active: true})
+	srv.SeedHook("acme", "web", gitea.CreateHookOption{Type: gitea.HookTypeDiscord, Config: map[string]string{"url": "https://discord.com/x"}})
+	c := srv.Client("alice")
+	ctx := context.Background()
+
+	targets, err := c.OrgRepoHookTargets(ctx, "acme")
+	assert.NoError(t, err)
+	assert.Len(t, targets, 3)
+	targets = append(targets, gitea.HookTarget{Owner: "acme", Repo: "missing"})
+	specs := []gitea.CreateHookOption{ci, chat}
+
+	actions := func(report *gitea.HookReport) []gitea.HookChangeAction {
+		var result []gitea.HookChangeAction
+		for _, change := range report.Changes {
+			result = append(result, change.Action)
+		}
+		return result
+	}
+
+	reports := c.ReconcileHooks(ctx, targets, specs, gitea.ReconcileHooksOptions{DryRun: true, Prune: true})
+	if assert.Len(t, reports, 4) {
+		assert.Equal(t, []gitea.HookChangeAction{gitea.HookChangeNone, gitea.HookChangeNone}, actions(reports[0]))
+		assert.False(t, reports[0].Changed())
+		assert.Equal(t, []gitea.HookChangeAction{gitea.HookChangeUpdate, gitea.HookChangeCreate, gitea.HookChangeDelete}, actions(reports[1]))
+		assert.Equal(t, []gitea.HookChangeAction{gitea.HookChangeCreate, gitea.HookChangeCreate}, actions(reports[2]))
+		assert.ErrorIs(t, reports[3].Err, gitea.ErrNotFound)
+		assert.Contains(t, reports[1].String(), "update gitea hook https://ci.example.com/hook")
+	}
+	hooks, _, err := c.ListRepoHooks("acme", "docs", gitea.ListHooksOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, hooks, "dry runs change nothing")
+
+	reports = c.ReconcileHooks(ctx, targets[:3], specs, gitea.ReconcileHooksOptions{Prune: true, Concurrency: 2})
+	for _, report := range reports {
+		assert.NoError(t, report.Err, report.Target.String())
+	}
+	reports = c.ReconcileHooks(ctx, targets[:3], specs, gitea.ReconcileHooksOptions{DryRun: true, Prune: true})
+	for _, report := range reports {
+		assert.False(t, report.Changed(), report.String())
+	}
+
+	// the authorization header is returned by the API and compared
+	specs[0].AuthorizationHeader = "Bearer t0k3n"
+	reports = c.ReconcileHooks(ctx, targets[:3], specs, gitea.ReconcileHooksOptions{Prune: true})
+	for _, report := range reports {
+		assert.NoError(t, report.Err, report.Target.String())
+		assert.Equal(t, []gitea.HookChangeAction{gitea.HookChangeUpdate, gitea.HookChangeNone}, actions(report))
+	}
+	reports = c.ReconcileHooks(ctx, targets[:3], specs, gitea.ReconcileHooksOptions{DryRun: true, Prune: true})
+	for _, report := range reports {
+		assert.False(t, report.Changed(), report.String())
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	reports = c.ReconcileHooks(canceled, targets, specs, gitea.ReconcileHooksOptions{Concurrency: 1})
+	for _, report := range reports {
+		assert.ErrorIs(t, report.Err, context.Canceled, report.Target.String())
+	}
+}
@@ -0,0 +1,519 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// WebhookHandlerFunc handles the payload of a webhook, as returned by ParseWebhook
+type WebhookHandlerFunc func(ctx context.Context, event HookEventType, payload any) error
+
+// WebhookFilter reports whether a handler should receive a webhook
+type WebhookFilter func(event HookEventType, payload any) bool
+
+// WebhookError is the error of a webhook delivery, it is written as JSON response
+// and passed to the error handler of asynchronous deliveries
+type WebhookError struct {
+	StatusCode int           `json:"-"`
+	Message    string        `json:"message"`
+	Event      HookEventType `json:"event,omitempty"`
+	Delivery   string        `json:"delivery,omitempty"`
+	Err        error         `json:"-"`
+}
+
+// Error implements error
+func (e *WebhookError) Error() string {
+	if e.Delivery != "" {
+		return fmt.Sprintf("webhook %s (delivery %s): %s", e.Event, e.Delivery, e.Message)
+	}
+	return fmt.Sprintf("webhook %s: %s", e.Event, e.Message)
+}
+
+// Unwrap returns the error of the handler
+func (e *WebhookError) Unwrap() error {
+	return e.Err
+}
+
+// ErrWebhookQueueFull is returned when an asynchronous WebhookRouter cannot queue a delivery
+var ErrWebhookQueueFull = errors.New("webhook queue is full")
+
+type webhookRoute struct {
+	events  []HookEventType
+	handler WebhookHandlerFunc
+	filters []WebhookFilter
+}
+
+func (rt *webhookRoute) matches(event HookEventType, payload any) bool {
+	if len(rt.events) > 0 {
+		found := false
+		for _, e := range rt.events {
+			found = found || e == event
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, filter := range rt.filters {
+		if !filter(event, payload) {
+			return false
+		}
+	}
+	return true
+}
+
+type webhookJob struct {
+	ctx      context.Context
+	event    HookEventType
+	delivery string
+	payload  any
+	routes   []*webhookRoute
+}
+
+// WebhookRouter is a http.Handler dispatching webhooks to the handlers registered for their event.
+// It does not verify signatures, wrap it with VerifyWebhookSignatureMiddleware:
+//
+//	router := gitea.NewWebhookRouter()
+//	router.OnPush(handlePush, gitea.FilterRef("main"))
+//	http.Handle("/hook", gitea.VerifyWebhookSignatureMiddleware(secret)(router))
+//
+// Handlers run one after another, when one fails or panics the remaining handlers are skipped
+// and the delivery is answered with a JSON encoded WebhookError. Deliveries without handlers are
+// answered with 204 No Content.
+type WebhookRouter struct {
+	mutex  sync.RWMutex
+	routes []*webhookRoute
+
+	queue        chan *webhookJob
+	workers      sync.WaitGroup
+	closed       bool
+	errorHandler func(ctx context.Context, err *WebhookError)
+}
+
+// WebhookRouterOption configures a WebhookRouter
+type WebhookRouterOption func(*WebhookRouter)
+
+// WebhookAsync makes a WebhookRouter answer deliveries with 202 Accepted right away and run the
+// handlers on a pool of workers, so slow handlers do not hit the delivery timeout of Gitea.
+// Up to queueSize deliveries wait for a worker, further deliveries are rejected with
+// 503 Service Unavailable. Errors of handlers are passed to the WebhookErrorHandler.
+func WebhookAsync(workers, queueSize int) WebhookRouterOption {
+	return func(r *WebhookRouter) {
+		if workers < 1 {
+			workers = 1
+		}
+		if queueSize < 0 {
+			queueSize = 0
+		}
+		r.queue = make(chan *webhookJob, queueSize)
+		for i := 0; i < workers; i++ {
+			r.workers.Add(1)
+			go r.work()
+		}
+	}
+}
+
+// WebhookErrorHandler sets a function called with the errors of handlers, including panics
+func WebhookErrorHandler(handler func(ctx context.Context, err *WebhookError)) WebhookRouterOption {
+	return func(r *WebhookRouter) {
+		r.errorHandler = handler
+	}
+}
+
+// NewWebhookRouter creates a WebhookRouter, call Close to stop the workers of an asynchronous router
+func NewWebhookRouter(options ...WebhookRouterOption) *WebhookRouter {
+	r := &WebhookRouter{}
+	for _, opt := range options {
+		opt(r)
+	}
+	return r
+}
+
+// Handle registers a handler for events, no events registers it for all events
+func (r *WebhookRouter) Handle(events []HookEventType, handler WebhookHandlerFunc, filters ...WebhookFilter) {
+	r.mutex.Lock()
+	r.routes = append(r.routes, &webhookRoute{events: events, handler: handler, filters: filters})
+	r.mutex.Unlock()
+}
+
+// On registers a handler for a single event
+func (r *WebhookRouter) On(event HookEventType, handler WebhookHandlerFunc, filters ...WebhookFilter) {
+	r.Handle([]HookEventType{event}, handler, filters...)
+}
+
+// onTyped registers a handler with a typed payload
+func onTyped[T any](r *WebhookRouter, events []HookEventType, handler func(context.Context, *T) error, filters []WebhookFilter) {
+	r.Handle(events, func(ctx context.Context, _ HookEventType, payload any) error {
+		p, ok := payload.(*T)
+		if !ok {
+			return fmt.Errorf("unexpected payload type %T", payload)
+		}
+		return handler(ctx, p)
+	}, filters...)
+}
+
+var (
+	issueEvents = []HookEventType{
+		HookEventIssues, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone,
+	}
+	pullRequestEvents = []HookEventType{
+		HookEventPullRequest, HookEventPullRequestAssign, HookEventPullRequestLabel, HookEventPullRequestMilestone,
+		HookEventPullRequestSync, HookEventPullRequestReviewRequest,
+		HookEventPullRequestReviewApproved, HookEventPullRequestReviewRejected, HookEventPullRequestReviewComment,
+	}
+)
+
+// withAction prepends an action filter unless action is empty
+func withAction(action string, filters []WebhookFilter) []WebhookFilter {
+	if action == "" {
+		return filters
+	}
+	return append([]WebhookFilter{FilterAction(action)}, filters...)
+}
+
+// OnPush registers a handler for push events
+func (r *WebhookRouter) OnPush(handler func(context.Context, *PushPayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventPush}, handler, filters)
+}
+
+// OnCreate registers a handler for the creation of branches and tags
+func (r *WebhookRouter) OnCreate(handler func(context.Context, *CreatePayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventCreate}, handler, filters)
+}
+
+// OnDelete registers a handler for the deletion of branches and tags
+func (r *WebhookRouter) OnDelete(handler func(context.Context, *DeletePayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventDelete}, handler, filters)
+}
+
+// OnFork registers a handler for fork events
+func (r *WebhookRouter) OnFork(handler func(context.Context, *ForkPayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventFork}, handler, filters)
+}
+
+// OnIssues registers a handler for issue events including the assign, label and milestone variants,
+// an empty action matches all actions
+func (r *WebhookRouter) OnIssues(action HookIssueAction, handler func(context.Context, *IssuePayload) error, filters ...WebhookFilter) {
+	onTyped(r, issueEvents, handler, withAction(string(action), filters))
+}
+
+// OnIssueComment registers a handler for comments on issues and pull requests,
+// an empty action matches all actions
+func (r *WebhookRouter) OnIssueComment(action HookIssueCommentAction, handler func(context.Context, *IssueCommentPayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventIssueComment, HookEventPullRequestComment}, handler, withAction(string(action), filters))
+}
+
+// OnPullRequest registers a handler for pull request events including the review, sync, label,
+// assign and milestone variants, an empty action matches all actions
+func (r *WebhookRouter) OnPullRequest(action HookIssueAction, handler func(context.Context, *PullRequestPayload) error, filters ...WebhookFilter) {
+	onTyped(r, pullRequestEvents, handler, withAction(string(action), filters))
+}
+
+// OnRelease registers a handler for release events, an empty action matches all actions
+func (r *WebhookRouter) OnRelease(action HookReleaseAction, handler func(context.Context, *ReleasePayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventRelease}, handler, withAction(string(action), filters))
+}
+
+// OnRepository registers a handler for the creation and deletion of repositories
+func (r *WebhookRouter) OnRepository(action HookRepoAction, handler func(context.Context, *RepositoryPayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventRepository}, handler, withAction(string(action), filters))
+}
+
+// OnWiki registers a handler for wiki events, an empty action matches all actions
+func (r *WebhookRouter) OnWiki(action HookWikiAction, handler func(context.Context, *WikiPayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventWiki}, handler, withAction(string(action), filters))
+}
+
+// OnPackage registers a handler for package events, an empty action matches all actions
+func (r *WebhookRouter) OnPackage(action HookPackageAction, handler func(context.Context, *PackagePayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventPackage}, handler, withAction(string(action), filters))
+}
+
+// OnStatus registers a handler for commit status events
+func (r *WebhookRouter) OnStatus(handler func(context.Context, *CommitStatusPayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventStatus}, handler, filters)
+}
+
+// OnWorkflowRun registers a handler for workflow run events, an empty action matches all actions
+func (r *WebhookRouter) OnWorkflowRun(action string, handler func(context.Context, *WorkflowRunPayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventWorkflowRun}, handler, withAction(action, filters))
+}
+
+// OnWorkflowJob registers a handler for workflow job events, an empty action matches all actions
+func (r *WebhookRouter) OnWorkflowJob(action string, handler func(context.Context, *WorkflowJobPayload) error, filters ...WebhookFilter) {
+	onTyped(r, []HookEventType{HookEventWorkflowJob}, handler, withAction(action, filters))
+}
+
+// ServeHTTP implements http.Handler
+func (r *WebhookRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	event := WebhookEventType(req)
+	delivery := req.Header.Get("X-Gitea-Delivery")
+	payload, err := ParseWebhook(req)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrUnknownWebhookEvent) {
+			status = http.StatusNotImplemented
+		}
+		writeWebhookError(w, &WebhookError{StatusCode: status, Message: err.Error(), Event: event, Delivery: delivery, Err: err})
+		return
+	}
+
+	r.mutex.RLock()
+	var routes []*webhookRoute
+	for _, rt := range r.routes {
+		if rt.matches(event, payload) {
+			routes = append(routes, rt)
+		}
+	}
+	r.mutex.RUnlock()
+	if len(routes) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	job := &webhookJob{ctx: req.Context(), event: event, delivery: delivery, payload: payload, routes: routes}
+	if r.queue == nil {
+		if err := r.run(job); err != nil {
+			writeWebhookError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// the handlers outlive the request
+	job.ctx = context.WithoutCancel(req.Context())
+	if err := r.enqueue(job); err != nil {
+		writeWebhookError(w, &WebhookError{StatusCode: http.StatusServiceUnavailable, Message: err.Error(), Event: event, Delivery: delivery, Err: err})
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// enqueue queues a job without blocking, it fails if the queue is full or closed
+func (r *WebhookRouter) enqueue(job *webhookJob) error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if r.closed {
+		return errors.New("webhook router is closed")
+	}
+	select {
+	case r.queue <- job:
+		return nil
+	default:
+		return ErrWebhookQueueFull
+	}
+}
+
+func (r *WebhookRouter) work() {
+	defer r.workers.Done()
+	for job := range r.queue {
+		if err := r.run(job); err != nil && r.errorHandler != nil {
+			r.errorHandler(job.ctx, err)
+		}
+	}
+}
+
+// run calls the handlers of a job, stopping at the first error
+func (r *WebhookRouter) run(job *webhookJob) *WebhookError {
+	for _, rt := range job.routes {
+		if err := callWebhookHandler(job.ctx, rt.handler, job.event, job.payload); err != nil {
+			werr := &WebhookError{StatusCode: http.StatusInternalServerError, Message: err.Error(), Event: job.event, Delivery: job.delivery, Err: err}
+			if r.queue == nil && r.errorHandler != nil {
+				r.errorHandler(job.ctx, werr)
+			}
+			return werr
+		}
+	}
+	return nil
+}
+
+// callWebhookHandler calls handler, turning a panic into an error
+func callWebhookHandler(ctx context.Context, handler WebhookHandlerFunc, event HookEventType, payload any) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("webhook handler panicked: %v", p)
+		}
+	}()
+	return handler(ctx, event, payload)
+}
+
+// Close stops accepting asynchronous deliveries and waits until the queued ones are handled
+// or ctx is done. It does nothing for synchronous routers.
+func (r *WebhookRouter) Close(ctx context.Context) error {
+	if r.queue == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mutex.Unlock()
+	done := make(chan struct{})
+	go func() {
+		r.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func writeWebhookError(w http.ResponseWriter, err *WebhookError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(err.StatusCode)
+	_ = json.NewEncoder(w).Encode(err)
+}
+
+// FilterRepository matches webhooks of the repositories with the given full names, e.g. "owner/repo"
+func FilterRepository(fullNames ...string) WebhookFilter {
+	return func(_ HookEventType, payload any) bool {
+		repo := webhookRepository(payload)
+		if repo == nil {
+			return false
+		}
+		for _, name := range fullNames {
+			if strings.EqualFold(repo.FullName, name) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FilterRef matches webhooks of refs matching one of the glob patterns of path.Match.
+// Patterns are matched against the full ref like "refs/heads/main" as well as the branch
+// or tag name like "main". The ref of pull request events is their base branch.
+func FilterRef(patterns ...string) WebhookFilter {
+	return func(_ HookEventType, payload any) bool {
+		ref := webhookRef(payload)
+		if ref == "" {
+			return false
+		}
+		short := strings.TrimPrefix(strings.TrimPrefix(ref, "refs/heads/"), "refs/tags/")
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, ref); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, short); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FilterAction matches webhooks with one of the given actions, e.g. "opened"
+func FilterAction(actions ...string) WebhookFilter {
+	return func(_ HookEventType, payload any) bool {
+		action := webhookAction(payload)
+		for _, a := range actions {
+			if a == action {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// webhookRepository returns the repository of a payload
+func webhookRepository(payload any) *Repository {
+	switch p := payload.(type) {
+	case *CreatePayload:
+		return p.Repo
+	case *DeletePayload:
+		return p.Repo
+	case *ForkPayload:
+		return p.Repo
+	case *PushPayload:
+		return p.Repo
+	case *IssuePayload:
+		return p.Repository
+	case *IssueCommentPayload:
+		return p.Repository
+	case *PullRequestPayload:
+		return p.Repository
+	case *ReleasePayload:
+		return p.Repository
+	case *RepositoryPayload:
+		return p.Repository
+	case *WikiPayload:
+		return p.Repository
+	case *PackagePayload:
+		return p.Repository
+	case *CommitStatusPayload:
+		return p.Repo
+	case *WorkflowRunPayload:
+		return p.Repo
+	case *WorkflowJobPayload:
+		return p.Repo
+	}
+	return nil
+}
+
+// webhookRef returns the git ref of a payload
+func webhookRef(payload any) string {
+	switch p := payload.(type) {
+	case *CreatePayload:
+		return p.Ref
+	case *DeletePayload:
+		return p.Ref
+	case *PushPayload:
+		return p.Ref
+	case *PullRequestPayload:
+		if p.PullRequest != nil && p.PullRequest.Base != nil {
+			return p.PullRequest.Base.Ref
+		}
+	case *ReleasePayload:
+		if p.Release != nil {
+			return "refs/tags/" + p.Release.TagName
+		}
+	case *WorkflowRunPayload:
+		if p.WorkflowRun != nil {
+			return p.WorkflowRun.HeadBranch
+		}
+	case *WorkflowJobPayload:
+		if p.WorkflowJob != nil {
+			return p.WorkflowJob.HeadBranch
+		}
+	}
+	return ""
+}
+
+// webhookAction returns the action of a payload
+func webhookAction(payload any) string {
+	switch p := payload.(type) {
+	case *IssuePayload:
+		return string(p.Action)
+	case *IssueCommentPayload:
+		return string(p.Action)
+	case *PullRequestPayload:
+		return string(p.Action)
+	case *ReleasePayload:
+		return string(p.Action)
+	case *RepositoryPayload:
+		return string(p.Action)
+	case *WikiPayload:
+		return string(p.Action)
+	case *PackagePayload:
+		return string(p.Action)
+	case *WorkflowRunPayload:
+		return p.Action
+	case *WorkflowJobPayload:
+		return p.Action
+	}
+	return ""
+}
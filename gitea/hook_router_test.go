@@ -0,0 +1,128 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newWebhookRequest(eventType HookEventType, body, secret string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/hook", strings.NewReader(body))
+	r.Header.Set("X-Gitea-Event", eventType.Event())
+	r.Header.Set("X-Gitea-Event-Type", string(eventType))
+	r.Header.Set("X-Gitea-Delivery", "d1")
+	if secret != "" {
+		hasher := hmac.New(sha256.New, []byte(secret))
+		hasher.Write([]byte(body))
+		r.Header.Set("X-Gitea-Signature", hex.EncodeToString(hasher.Sum(nil)))
+	}
+	return r
+}
+
+func TestWebhookRouter(t *testing.T) {
+	router := NewWebhookRouter()
+	var pushes, prs []string
+	router.OnPush(func(_ context.Context, p *PushPayload) error {
+		pushes = append(pushes, p.Ref)
+		return nil
+	}, FilterRepository("alice/demo"), FilterRef("main", "release/*"))
+	router.OnPullRequest(HookIssueOpened, func(_ context.Context, p *PullRequestPayload) error {
+		prs = append(prs, p.PullRequest.Title)
+		return nil
+	})
+	router.OnRelease("", func(context.Context, *ReleasePayload) error {
+		panic("boom")
+	})
+	router.OnIssues("", func(context.Context, *IssuePayload) error {
+		return errors.New("failed")
+	})
+	handler := VerifyWebhookSignatureMiddleware("s3cr3t")(router)
+
+	serve := func(eventType HookEventType, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newWebhookRequest(eventType, body, "s3cr3t"))
+		return w
+	}
+
+	assert.Equal(t, http.StatusNoContent, serve(HookEventPush, `{"ref":"refs/heads/main","repository":{"full_name":"alice/demo"}}`).Code)
+	serve(HookEventPush, `{"ref":"refs/heads/release/1.0","repository":{"full_name":"Alice/Demo"}}`)
+	serve(HookEventPush, `{"ref":"refs/heads/feature","repository":{"full_name":"alice/demo"}}`)
+	serve(HookEventPush, `{"ref":"refs/heads/main","repository":{"full_name":"alice/other"}}`)
+	assert.Equal(t, []string{"refs/heads/main", "refs/heads/release/1.0"}, pushes)
+
+	serve(HookEventPullRequest, `{"action":"opened","pull_request":{"title":"one"}}`)
+	serve(HookEventPullRequest, `{"action":"closed","pull_request":{"title":"two"}}`)
+	assert.Equal(t, []string{"one"}, prs)
+
+	w := serve(HookEventRelease, `{"action":"published"}`)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	var werr WebhookError
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&werr))
+	assert.Contains(t, werr.Message, "boom")
+	assert.Equal(t, HookEventRelease, werr.Event)
+	assert.Equal(t, "d1", werr.Delivery)
+
+	assert.Equal(t, http.StatusInternalServerError, serve(HookEventIssueLabel, `{"action":"label_updated"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(HookEventPush, `{`).Code)
+	assert.Equal(t, http.StatusNotImplemented, serve("unknown", `{}`).Code)
+	assert.Equal(t, http.StatusNoContent, serve(HookEventFork, `{}`).Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newWebhookRequest(HookEventPush, `{}`, "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestWebhookRouterAsync(t *testing.T) {
+	var mutex sync.Mutex
+	var errs []*WebhookError
+	release := make(chan struct{})
+	router := NewWebhookRouter(WebhookAsync(1, 1), WebhookErrorHandler(func(_ context.Context, err *WebhookError) {
+		mutex.Lock()
+		errs = append(errs, err)
+		mutex.Unlock()
+	}))
+	router.OnPush(func(ctx context.Context, _ *PushPayload) error {
+		<-release
+		return ctx.Err()
+	})
+	router.OnDelete(func(context.Context, *DeletePayload) error {
+		return errors.New("failed")
+	})
+
+	serve := func(eventType HookEventType) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, newWebhookRequest(eventType, `{}`, ""))
+		return w.Code
+	}
+
+	// the first delivery blocks the worker, the second one waits in the queue
+	assert.Equal(t, http.StatusAccepted, serve(HookEventPush))
+	assert.Eventually(t, func() bool { return len(router.queue) == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, http.StatusAccepted, serve(HookEventDelete))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(HookEventPush))
+	close(release)
+
+	assert.NoError(t, router.Close(context.Background()))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(HookEventPush))
+	mutex.Lock()
+	defer mutex.Unlock()
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, HookEventDelete, errs[0].Event)
+		assert.EqualError(t, errors.Unwrap(errs[0]), "failed")
+	}
+}
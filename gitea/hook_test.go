@@ -0,0 +1,44 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestRepoHook(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		if r.URL.Path == "/api/v1/repos/alice/demo/hooks/404/tests" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	c, err := NewClient(server.URL, SetGiteaVersion(""))
+	assert.NoError(t, err)
+
+	resp, err := c.TestRepoHook("alice", "demo", 7, "")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	_, err = c.TestRepoHook("alice", "demo", 7, "release/v1.0")
+	assert.NoError(t, err)
+	_, err = c.TestRepoHook("alice", "demo", 404, "")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = c.TestRepoHook("alice", "", 7, "")
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{
+		"POST /api/v1/repos/alice/demo/hooks/7/tests",
+		"POST /api/v1/repos/alice/demo/hooks/7/tests?ref=release%2Fv1.0",
+		"POST /api/v1/repos/alice/demo/hooks/404/tests",
+	}, requests)
+}
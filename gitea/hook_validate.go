@@ -26,8 +26,25 @@ func VerifyWebhookSignature(secret, expected string, payload []byte) (bool, erro
 	return hmac.Equal(hash.Sum(nil), expectedSum), nil
 }
 
+// VerifyWebhookSignatures verifies that a payload matches the X-Gitea-Signature based on any of the secrets
+func VerifyWebhookSignatures(secrets []string, expected string, payload []byte) (bool, error) {
+	for _, secret := range secrets {
+		ok, err := VerifyWebhookSignature(secret, expected, payload)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
 // VerifyWebhookSignatureMiddleware is a http.Handler for verifying X-Gitea-Signature on incoming webhooks
 func VerifyWebhookSignatureMiddleware(secret string) func(http.Handler) http.Handler {
+	return VerifyWebhookSignaturesMiddleware(secret)
+}
+
+// VerifyWebhookSignaturesMiddleware is like VerifyWebhookSignatureMiddleware, but accepts webhooks signed
+// with any of the secrets, e.g. the old and the new secret while rotating it
+func VerifyWebhookSignaturesMiddleware(secrets ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			var b bytes.Buffer
@@ -42,7 +59,7 @@ func VerifyWebhookSignatureMiddleware(secret string) func(http.Handler) http.Han
 				return
 			}
 
-			ok, err := VerifyWebhookSignature(secret, expected, b.Bytes())
+			ok, err := VerifyWebhookSignatures(secrets, expected, b.Bytes())
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusUnauthorized)
 				return
@@ -0,0 +1,421 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package hookformat renders Gitea webhooks into chat messages, to relay events
+// to chat systems Gitea has no hook type for.
+//
+//	f := hookformat.New()
+//	relay := f.Relay(func(ctx context.Context, msg *hookformat.Message) error {
+//		return postToChat(ctx, msg.Markdown)
+//	})
+//	http.Handle("/hook", gitea.VerifyWebhookSignatureMiddleware(secret)(relay))
+//
+// Events are rendered as plain text, Markdown or a Card with a title, fields and links.
+// The text and Markdown templates use text/template with the payload of the event
+// (e.g. *gitea.PushPayload) as data and can be replaced with SetTemplate.
+package hookformat // import "code.gitea.io/sdk/gitea/hookformat"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// Format is the output format of a template
+type Format string
+
+const (
+	// FormatText renders plain text
+	FormatText Format = "text"
+	// FormatMarkdown renders Markdown
+	FormatMarkdown Format = "markdown"
+)
+
+// Card is a generic rich message, to be mapped to the message format of a chat system
+type Card struct {
+	Event gitea.HookEventType
+	Title string
+	// URL is the page of the subject of the event, e.g. the pull request
+	URL  string
+	Text string
+	// Color is a hex color reflecting the outcome of the event, e.g. green for merged pull requests
+	Color  string
+	Fields []Field
+	Links  []Link
+}
+
+// Field is a name and value pair of a Card
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Link is a titled link of a Card
+type Link struct {
+	Title string
+	URL   string
+}
+
+// Message is an event rendered in all formats
+type Message struct {
+	Event    gitea.HookEventType
+	Payload  any
+	Text     string
+	Markdown string
+	Card     *Card
+}
+
+const (
+	colorGreen  = "#21ba45"
+	colorRed    = "#db2828"
+	colorPurple = "#a333c8"
+	colorBlue   = "#2185d0"
+	colorGrey   = "#767676"
+)
+
+// Formatter renders webhooks, it is safe for concurrent use
+type Formatter struct {
+	mutex     sync.RWMutex
+	templates map[Format]map[gitea.HookEventType]*template.Template
+}
+
+// New creates a Formatter with the default templates
+func New() *Formatter {
+	f := &Formatter{templates: map[Format]map[gitea.HookEventType]*template.Template{
+		FormatText:     {},
+		FormatMarkdown: {},
+	}}
+	for event, text := range textTemplates {
+		if err := f.SetTemplate(FormatText, event, text); err != nil {
+			panic(err)
+		}
+	}
+	for event, text := range markdownTemplates {
+		if err := f.SetTemplate(FormatMarkdown, event, text); err != nil {
+			panic(err)
+		}
+	}
+	return f
+}
+
+// SetTemplate replaces the template of an event. The templates of issues, issue_comment
+// and pull_request events are used for their variants, unless they have their own template,
+// e.g. a template for gitea.HookEventPullRequestReviewApproved only renders approvals.
+// Besides the functions of text/template, templates can use:
+//
+//	user, repo          the name of a *gitea.User or the full name of a *gitea.Repository
+//	refName, shortSHA   the branch or tag name of a ref and the first 10 characters of a SHA
+//	refURL, wikiURL     the escaped url of a ref and of a wiki page of a *gitea.Repository
+//	firstLine, truncate the first line of a text and a text cut to a number of characters
+//	plural              "1 commit" or "2 commits" for plural 2 "commit"
+//	md, link, repoLink  Markdown escaped text, a Markdown link and a link to a repository
+//	issueAction         the verb of an issue action, e.g. "updated the labels of"
+//	pullAction          the verb of a pull request payload, e.g. "merged" or "approved"
+//	commitCount         the number of commits of a push
+func (f *Formatter) SetTemplate(format Format, event gitea.HookEventType, text string) error {
+	tmpl, err := template.New(string(event)).Funcs(funcs).Parse(text)
+	if err != nil {
+		return err
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	templates, ok := f.templates[format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", format)
+	}
+	templates[event] = tmpl
+	return nil
+}
+
+// baseEvent returns the event whose template is used for the variants of an event
+func baseEvent(event gitea.HookEventType) gitea.HookEventType {
+	switch event.Event() {
+	case "issues":
+		return gitea.HookEventIssues
+	case "issue_comment":
+		return gitea.HookEventIssueComment
+	case "pull_request", "pull_request_approved", "pull_request_rejected", "pull_request_comment":
+		return gitea.HookEventPullRequest
+	}
+	return event
+}
+
+// Render renders the payload of an event with the template of a format
+func (f *Formatter) Render(format Format, event gitea.HookEventType, payload any) (string, error) {
+	f.mutex.RLock()
+	templates := f.templates[format]
+	tmpl, ok := templates[event]
+	if !ok {
+		tmpl, ok = templates[baseEvent(event)]
+	}
+	f.mutex.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("no %s template for event %q", format, event)
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, payload); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Text renders the payload of an event as plain text
+func (f *Formatter) Text(event gitea.HookEventType, payload any) (string, error) {
+	return f.Render(FormatText, event, payload)
+}
+
+// Markdown renders the payload of an event as Markdown
+func (f *Formatter) Markdown(event gitea.HookEventType, payload any) (string, error) {
+	return f.Render(FormatMarkdown, event, payload)
+}
+
+// Card renders the payload of an event as Card, the title and text are
+// the first and the following lines of the text template
+func (f *Formatter) Card(event gitea.HookEventType, payload any) (*Card, error) {
+	text, err := f.Text(event, payload)
+	if err != nil {
+		return nil, err
+	}
+	card := &Card{Event: event, Color: colorGrey}
+	card.Title, card.Text, _ = strings.Cut(text, "\n")
+	fillCard(card, payload)
+	return card, nil
+}
+
+// Message renders the payload of an event in all formats
+func (f *Formatter) Message(event gitea.HookEventType, payload any) (*Message, error) {
+	msg := &Message{Event: event, Payload: payload}
+	var err error
+	if msg.Text, err = f.Text(event, payload); err != nil {
+		return nil, err
+	}
+	if msg.Markdown, err = f.Markdown(event, payload); err != nil {
+		return nil, err
+	}
+	if msg.Card, err = f.Card(event, payload); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// Relay returns a http.Handler parsing webhooks with gitea.ParseWebhook and passing them
+// rendered to send. It answers unknown events with 204 No Content, so they are ignored,
+// and errors of send with 502 Bad Gateway. It does not verify signatures, see
+// gitea.VerifyWebhookSignatureMiddleware.
+func (f *Formatter) Relay(send func(ctx context.Context, msg *Message) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event := gitea.WebhookEventType(r)
+		payload, err := gitea.ParseWebhook(r)
+		if errors.Is(err, gitea.ErrUnknownWebhookEvent) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		msg, err := f.Message(event, payload)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := send(r.Context(), msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// fillCard sets the url, color, fields and links of a card
+func fillCard(card *Card, payload any) {
+	var repo *gitea.Repository
+	var sender *gitea.User
+	switch p := payload.(type) {
+	case *gitea.PushPayload:
+		repo, sender = p.Repo, p.Pusher
+		card.URL = p.CompareURL
+		card.Color = colorBlue
+		card.addField(refKind(p.Ref), refName(p.Ref))
+		card.addField("Commits", fmt.Sprint(commitCount(p)))
+	case *gitea.CreatePayload:
+		repo, sender = p.Repo, p.Sender
+		card.Color = colorGreen
+		card.addField(capitalize(p.RefType), refName(p.Ref))
+	case *gitea.DeletePayload:
+		repo, sender = p.Repo, p.Sender
+		card.Color = colorRed
+		card.addField(capitalize(p.RefType), refName(p.Ref))
+	case *gitea.ForkPayload:
+		repo, sender = p.Repo, p.Sender
+		if p.Forkee != nil {
+			card.URL = p.Forkee.HTMLURL
+			card.addLink("Fork", p.Forkee.HTMLURL)
+		}
+	case *gitea.IssuePayload:
+		repo, sender = p.Repository, p.Sender
+		card.Color = actionColor(string(p.Action))
+		if p.Issue != nil {
+			card.URL = p.Issue.HTMLURL
+			card.addField("State", string(p.Issue.State))
+			card.addField("Labels", labelNames(p.Issue.Labels))
+			card.addField("Assignees", userNames(p.Issue.Assignees))
+			if p.Issue.Milestone != nil {
+				card.addField("Milestone", p.Issue.Milestone.Title)
+			}
+			card.addLink("Issue", p.Issue.HTMLURL)
+		}
+	case *gitea.IssueCommentPayload:
+		repo, sender = p.Repository, p.Sender
+		card.Color = actionColor(string(p.Action))
+		if p.Comment != nil {
+			card.URL = p.Comment.HTMLURL
+			card.addLink("Comment", p.Comment.HTMLURL)
+		}
+		if p.Issue != nil && p.IsPull {
+			card.addLink("Pull request", p.Issue.HTMLURL)
+		} else if p.Issue != nil {
+			card.addLink("Issue", p.Issue.HTMLURL)
+		}
+	case *gitea.PullRequestPayload:
+		repo, sender = p.Repository, p.Sender
+		card.Color = actionColor(pullAction(p))
+		if pr := p.PullRequest; pr != nil {
+			card.URL = pr.HTMLURL
+			if pr.Head != nil && pr.Base != nil {
+				card.addField("Branches", pr.Head.Name+" → "+pr.Base.Name)
+			}
+			card.addField("State", string(pr.State))
+			card.addField("Labels", labelNames(pr.Labels))
+			card.addField("Assignees", userNames(pr.Assignees))
+			if pr.Milestone != nil {
+				card.addField("Milestone", pr.Milestone.Title)
+			}
+			card.addLink("Pull request", pr.HTMLURL)
+			card.addLink("Diff", pr.DiffURL)
+		}
+		if p.RequestedReviewer != nil {
+			card.addField("Reviewer", p.RequestedReviewer.UserName)
+		}
+	case *gitea.ReleasePayload:
+		repo, sender = p.Repository, p.Sender
+		card.Color = actionColor(string(p.Action))
+		if rel := p.Release; rel != nil {
+			card.URL = rel.HTMLURL
+			card.addField("Tag", rel.TagName)
+			if rel.IsPrerelease {
+				card.addField("Pre-release", "yes")
+			}
+			card.addLink("Release", rel.HTMLURL)
+			card.addLink("Source code (tar.gz)", rel.TarURL)
+		}
+	case *gitea.RepositoryPayload:
+		repo, sender = p.Repository, p.Sender
+		card.Color = actionColor(string(p.Action))
+	case *gitea.WikiPayload:
+		repo, sender = p.Repository, p.Sender
+		card.Color = actionColor(string(p.Action))
+		card.addField("Page", p.Page)
+		card.URL = wikiURL(repo, p.Page)
+	case *gitea.PackagePayload:
+		repo, sender = p.Repository, p.Sender
+		card.Color = actionColor(string(p.Action))
+		if p.Package != nil {
+			card.addField("Type", p.Package.Type)
+			card.addField("Version", p.Package.Version)
+		}
+	case *gitea.CommitStatusPayload:
+		repo, sender = p.Repo, p.Sender
+		card.URL = p.TargetURL
+		card.Color = actionColor(p.State)
+		card.addField("Context", p.Context)
+		card.addField("State", p.State)
+		card.addField("Commit", shortSHA(p.SHA))
+		card.addLink("Details", p.TargetURL)
+	case *gitea.WorkflowRunPayload:
+		repo, sender = p.Repo, p.Sender
+		if run := p.WorkflowRun; run != nil {
+			card.URL = run.HTMLURL
+			card.Color = actionColor(run.Conclusion)
+			card.addField("Branch", run.HeadBranch)
+			card.addField("Event", run.Event)
+			card.addField("Conclusion", run.Conclusion)
+			card.addLink("Run", run.HTMLURL)
+		}
+	case *gitea.WorkflowJobPayload:
+		repo, sender = p.Repo, p.Sender
+		if job := p.WorkflowJob; job != nil {
+			card.URL = job.HTMLURL
+			card.Color = actionColor(job.Conclusion)
+			card.addField("Branch", job.HeadBranch)
+			card.addField("Runner", job.RunnerName)
+			card.addField("Conclusion", job.Conclusion)
+			card.addLink("Job", job.HTMLURL)
+		}
+	}
+	if repo != nil {
+		card.Fields = append([]Field{{Name: "Repository", Value: repo.FullName}}, card.Fields...)
+		card.addLink("Repository", repo.HTMLURL)
+	}
+	if sender != nil {
+		card.addField("By", sender.UserName)
+	}
+}
+
+func (c *Card) addField(name, value string) {
+	if value != "" {
+		c.Fields = append(c.Fields, Field{Name: name, Value: value})
+	}
+}
+
+func (c *Card) addLink(title, url string) {
+	if url != "" {
+		c.Links = append(c.Links, Link{Title: title, URL: url})
+	}
+}
+
+// actionColor returns the color of the outcome of an action, state or conclusion
+func actionColor(action string) string {
+	switch action {
+	case "opened", "reopened", "created", "published", "approved", "success":
+		return colorGreen
+	case "closed", "deleted", "requested changes on", "failure", "error", "cancelled":
+		return colorRed
+	case "merged":
+		return colorPurple
+	case "pending", "running", "waiting":
+		return colorBlue
+	}
+	return colorGrey
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func labelNames(labels []*gitea.Label) string {
+	names := make([]string, 0, len(labels))
+	for _, l := range labels {
+		names = append(names, l.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func userNames(users []*gitea.User) string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		names = append(names, u.UserName)
+	}
+	return strings.Join(names, ", ")
+}
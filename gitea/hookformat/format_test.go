@@ -0,0 +1,136 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package hookformat
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+	"code.gitea.io/sdk/gitea/giteatest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatter(t *testing.T) {
+	f := New()
+	for _, event := range gitea.WebhookEventTypes() {
+		msg, err := f.Message(event, giteatest.WebhookFixture(event))
+		if assert.NoError(t, err, event) {
+			assert.NotEmpty(t, msg.Text, event)
+			assert.NotEmpty(t, msg.Markdown, event)
+			assert.NotEmpty(t, msg.Card.Title, event)
+			assert.NotContains(t, msg.Text, "<no value>", event)
+			assert.NotContains(t, msg.Markdown, "<no value>", event)
+		}
+	}
+
+	text, err := f.Text(gitea.HookEventPush, giteatest.WebhookFixture(gitea.HookEventPush))
+	assert.NoError(t, err)
+	assert.Equal(t, "[alice/demo] alice pushed 1 commit to main\n4015b57a14 Fix the build", text)
+
+	text, err = f.Text(gitea.HookEventPullRequestReviewApproved, giteatest.WebhookFixture(gitea.HookEventPullRequestReviewApproved))
+	assert.NoError(t, err)
+	assert.Equal(t, "[alice/demo] bob approved pull request #2: Fix the build\nLooks good to me", text)
+
+	pr := giteatest.WebhookFixture(gitea.HookEventPullRequest).(*gitea.PullRequestPayload)
+	pr.Action = gitea.HookIssueClosed
+	pr.PullRequest.HasMerged = true
+	pr.PullRequest.Title = "Use *bold* [links]"
+	md, err := f.Markdown(gitea.HookEventPullRequest, pr)
+	assert.NoError(t, err)
+	assert.Contains(t, md, `**alice** merged pull request [#2: Use \*bold\* \[links\]](https://gitea.example.com/alice/demo/pulls/2)`)
+
+	card, err := f.Card(gitea.HookEventPullRequest, pr)
+	assert.NoError(t, err)
+	assert.Equal(t, "[alice/demo] alice merged pull request #2: Use *bold* [links]", card.Title)
+	assert.Equal(t, "https://gitea.example.com/alice/demo/pulls/2", card.URL)
+	assert.Equal(t, colorPurple, card.Color)
+	assert.Equal(t, Field{Name: "Repository", Value: "alice/demo"}, card.Fields[0])
+	assert.Contains(t, card.Fields, Field{Name: "Branches", Value: "fix-build → main"})
+	assert.Equal(t, Field{Name: "By", Value: "alice"}, card.Fields[len(card.Fields)-1])
+	assert.Contains(t, card.Links, Link{Title: "Diff", URL: "https://gitea.example.com/alice/demo/pulls/2.diff"})
+
+	push := giteatest.WebhookFixture(gitea.HookEventPush).(*gitea.PushPayload)
+	push.Ref = "refs/tags/v1.0 (rc)"
+	md, err = f.Markdown(gitea.HookEventPush, push)
+	assert.NoError(t, err)
+	assert.Contains(t, md, `pushed 1 commit to [v1.0 (rc)](https://gitea.example.com/alice/demo/src/tag/v1.0%20%28rc%29)`)
+	push.Ref = "refs/heads/release/v1"
+	md, err = f.Markdown(gitea.HookEventPush, push)
+	assert.NoError(t, err)
+	assert.Contains(t, md, `[release/v1](https://gitea.example.com/alice/demo/src/branch/release/v1)`)
+	card, err = f.Card(gitea.HookEventPush, push)
+	assert.NoError(t, err)
+	assert.Contains(t, card.Fields, Field{Name: "Branch", Value: "release/v1"})
+
+	wiki := giteatest.WebhookFixture(gitea.HookEventWiki).(*gitea.WikiPayload)
+	wiki.Page = "Setup (Linux)"
+	md, err = f.Markdown(gitea.HookEventWiki, wiki)
+	assert.NoError(t, err)
+	assert.Contains(t, md, `[Setup (Linux)](https://gitea.example.com/alice/demo/wiki/Setup%20%28Linux%29)`)
+	card, err = f.Card(gitea.HookEventWiki, wiki)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://gitea.example.com/alice/demo/wiki/Setup%20%28Linux%29", card.URL)
+
+	_, err = f.Text("unknown", pr)
+	assert.Error(t, err)
+}
+
+func TestFormatterSetTemplate(t *testing.T) {
+	f := New()
+	assert.Error(t, f.SetTemplate(FormatText, gitea.HookEventPush, "{{.Ref"))
+	assert.Error(t, f.SetTemplate("html", gitea.HookEventPush, "{{.Ref}}"))
+
+	assert.NoError(t, f.SetTemplate(FormatText, gitea.HookEventPush, `{{user .Pusher}} → {{refName .Ref}} ({{plural (commitCount .) "commit"}})`))
+	text, err := f.Text(gitea.HookEventPush, giteatest.WebhookFixture(gitea.HookEventPush))
+	assert.NoError(t, err)
+	assert.Equal(t, "alice → main (1 commit)", text)
+
+	// a variant template only replaces the variant
+	assert.NoError(t, f.SetTemplate(FormatText, gitea.HookEventPullRequestReviewApproved, `LGTM from {{user .Sender}}`))
+	text, err = f.Text(gitea.HookEventPullRequestReviewApproved, giteatest.WebhookFixture(gitea.HookEventPullRequestReviewApproved))
+	assert.NoError(t, err)
+	assert.Equal(t, "LGTM from bob", text)
+	text, err = f.Text(gitea.HookEventPullRequestReviewRejected, giteatest.WebhookFixture(gitea.HookEventPullRequestReviewRejected))
+	assert.NoError(t, err)
+	assert.Equal(t, "[alice/demo] bob requested changes on pull request #2: Fix the build\nPlease add a test", text)
+}
+
+func TestRelay(t *testing.T) {
+	var sent []*Message
+	var sendErr error
+	relay := New().Relay(func(_ context.Context, msg *Message) error {
+		sent = append(sent, msg)
+		return sendErr
+	})
+	handler := gitea.VerifyWebhookSignatureMiddleware("s3cr3t")(relay)
+	sender := &giteatest.WebhookSender{Secret: "s3cr3t"}
+
+	rec, err := sender.Serve(handler, gitea.HookEventRelease, giteatest.WebhookFixture(gitea.HookEventRelease))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	if assert.Len(t, sent, 1) {
+		assert.Equal(t, gitea.HookEventRelease, sent[0].Event)
+		assert.IsType(t, &gitea.ReleasePayload{}, sent[0].Payload)
+		assert.Contains(t, sent[0].Text, "published release v1.0.0")
+	}
+
+	rec, err = (&giteatest.WebhookSender{Secret: "wrong"}).Serve(handler, gitea.HookEventRelease, giteatest.WebhookFixture(gitea.HookEventRelease))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec, err = sender.Serve(handler, "unknown", map[string]string{})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Len(t, sent, 1)
+
+	sendErr = errors.New("chat is down")
+	rec, err = sender.Serve(handler, gitea.HookEventPush, giteatest.WebhookFixture(gitea.HookEventPush))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
@@ -0,0 +1,193 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package hookformat
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// funcs are the functions available in templates, see Formatter.SetTemplate
+var funcs = template.FuncMap{
+	"user":        userName,
+	"repo":        repoName,
+	"refName":     refName,
+	"refURL":      refURL,
+	"wikiURL":     wikiURL,
+	"shortSHA":    shortSHA,
+	"firstLine":   firstLine,
+	"truncate":    truncate,
+	"plural":      plural,
+	"md":          escapeMarkdown,
+	"link":        markdownLink,
+	"repoLink":    repoLink,
+	"issueAction": issueAction,
+	"pullAction":  pullAction,
+	"commitCount": commitCount,
+}
+
+func userName(u *gitea.User) string {
+	if u == nil || u.UserName == "" {
+		return "someone"
+	}
+	return u.UserName
+}
+
+func repoName(r *gitea.Repository) string {
+	if r == nil {
+		return ""
+	}
+	return r.FullName
+}
+
+// refName returns the branch or tag name of a ref
+func refName(ref string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+		if name, ok := strings.CutPrefix(ref, prefix); ok {
+			return name
+		}
+	}
+	return ref
+}
+
+// refURL returns the url of the source of a branch or tag in the web interface
+func refURL(r *gitea.Repository, ref string) string {
+	if r == nil || r.HTMLURL == "" {
+		return ""
+	}
+	kind := "/src/branch/"
+	if strings.HasPrefix(ref, "refs/tags/") {
+		kind = "/src/tag/"
+	}
+	return r.HTMLURL + kind + escapePath(refName(ref))
+}
+
+// refKind returns whether a ref is a branch or a tag
+func refKind(ref string) string {
+	if strings.HasPrefix(ref, "refs/tags/") {
+		return "Tag"
+	}
+	return "Branch"
+}
+
+// wikiURL returns the url of a wiki page in the web interface
+func wikiURL(r *gitea.Repository, page string) string {
+	if r == nil || r.HTMLURL == "" {
+		return ""
+	}
+	return r.HTMLURL + "/wiki/" + url.PathEscape(page)
+}
+
+// escapePath escapes the segments of a path, e.g. a branch name containing slashes
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 10 {
+		return sha[:10]
+	}
+	return sha
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
+
+// truncate cuts s to n characters, marking cut texts with an ellipsis
+func truncate(n int, s string) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
+
+// plural returns e.g. "1 commit" or "3 commits"
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`, `<`, `\<`, `>`, `\>`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// markdownLink returns a Markdown link, or the escaped text if url is empty
+func markdownLink(text, url string) string {
+	if url == "" {
+		return escapeMarkdown(text)
+	}
+	return fmt.Sprintf("[%s](%s)", escapeMarkdown(text), url)
+}
+
+func repoLink(r *gitea.Repository) string {
+	if r == nil {
+		return ""
+	}
+	return markdownLink(r.FullName, r.HTMLURL)
+}
+
+var issueActions = map[gitea.HookIssueAction]string{
+	gitea.HookIssueLabelUpdated:         "updated the labels of",
+	gitea.HookIssueLabelCleared:         "cleared the labels of",
+	gitea.HookIssueMilestoned:           "set the milestone of",
+	gitea.HookIssueDemilestoned:         "removed the milestone of",
+	gitea.HookIssueReviewRequested:      "requested a review for",
+	gitea.HookIssueReviewRequestRemoved: "removed the review request for",
+}
+
+// issueAction returns the verb of an issue or pull request action
+func issueAction(action gitea.HookIssueAction) string {
+	if verb, ok := issueActions[action]; ok {
+		return verb
+	}
+	return string(action)
+}
+
+// pullAction returns the verb of a pull request payload, telling merged from closed
+// pull requests and approving from rejecting reviews
+func pullAction(p *gitea.PullRequestPayload) string {
+	switch {
+	case p.Action == gitea.HookIssueClosed && p.PullRequest != nil && p.PullRequest.HasMerged:
+		return "merged"
+	case p.Action == gitea.HookIssueReviewed && p.Review != nil:
+		switch gitea.HookEventType(p.Review.Type) {
+		case gitea.HookEventPullRequestReviewApproved:
+			return "approved"
+		case gitea.HookEventPullRequestReviewRejected:
+			return "requested changes on"
+		case gitea.HookEventPullRequestReviewComment:
+			return "commented on"
+		}
+	case p.Action == gitea.HookIssueSynchronized:
+		return "pushed to"
+	}
+	return issueAction(p.Action)
+}
+
+// commitCount returns the number of commits of a push, which may be more than the commits sent
+func commitCount(p *gitea.PushPayload) int {
+	if p.TotalCommits > 0 {
+		return p.TotalCommits
+	}
+	return len(p.Commits)
+}
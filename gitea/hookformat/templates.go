@@ -0,0 +1,71 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package hookformat
+
+import "code.gitea.io/sdk/gitea"
+
+// textTemplates are the default templates of FormatText, the first line is the title of the event
+var textTemplates = map[gitea.HookEventType]string{
+	gitea.HookEventPush: `[{{repo .Repo}}] {{user .Pusher}} pushed {{plural (commitCount .) "commit"}} to {{refName .Ref}}` +
+		`{{range .Commits}}
+{{shortSHA .ID}} {{firstLine .Message}}{{end}}`,
+	gitea.HookEventCreate: `[{{repo .Repo}}] {{user .Sender}} created {{.RefType}} {{refName .Ref}}`,
+	gitea.HookEventDelete: `[{{repo .Repo}}] {{user .Sender}} deleted {{.RefType}} {{refName .Ref}}`,
+	gitea.HookEventFork:   `[{{repo .Repo}}] {{user .Sender}} forked the repository to {{repo .Forkee}}`,
+	gitea.HookEventIssues: `[{{repo .Repository}}] {{user .Sender}} {{issueAction .Action}} issue {{with .Issue}}#{{.Index}}: {{.Title}}` +
+		`{{if eq $.Action "opened"}}{{with .Body}}
+{{truncate 300 .}}{{end}}{{end}}{{end}}`,
+	gitea.HookEventIssueComment: `[{{repo .Repository}}] {{user .Sender}} {{.Action}} a comment on {{if .IsPull}}pull request{{else}}issue{{end}}` +
+		`{{with .Issue}} #{{.Index}}: {{.Title}}{{end}}{{if ne .Action "deleted"}}{{with .Comment}}
+{{truncate 300 .Body}}{{end}}{{end}}`,
+	gitea.HookEventPullRequest: `[{{repo .Repository}}] {{user .Sender}} {{pullAction .}} pull request {{with .PullRequest}}#{{.Index}}: {{.Title}}{{end}}` +
+		`{{with .Review}}{{with .Content}}
+{{truncate 300 .}}{{end}}{{end}}`,
+	gitea.HookEventRelease:    `[{{repo .Repository}}] {{user .Sender}} {{.Action}} release {{with .Release}}{{.TagName}}{{if and .Title (ne .Title .TagName)}}: {{.Title}}{{end}}{{end}}`,
+	gitea.HookEventRepository: `{{user .Sender}} {{.Action}} repository {{repo .Repository}}`,
+	gitea.HookEventWiki:       `[{{repo .Repository}}] {{user .Sender}} {{.Action}} wiki page {{.Page}}{{with .Comment}}: {{.}}{{end}}`,
+	gitea.HookEventPackage:    `{{user .Sender}} {{.Action}} package {{with .Package}}{{.Name}} {{.Version}} ({{.Type}}){{end}}`,
+	gitea.HookEventStatus:     `[{{repo .Repo}}] {{.Context}} is {{.State}} on {{shortSHA .SHA}}{{with .Description}}: {{.}}{{end}}`,
+	gitea.HookEventWorkflowRun: `[{{repo .Repo}}] workflow run {{with .WorkflowRun}}#{{.RunNumber}} {{.DisplayTitle}}{{end}} {{.Action}}` +
+		`{{with .WorkflowRun}}{{with .Conclusion}}: {{.}}{{end}}{{end}}`,
+	gitea.HookEventWorkflowJob: `[{{repo .Repo}}] job {{with .WorkflowJob}}{{.Name}}{{end}} {{.Action}}` +
+		`{{with .WorkflowJob}}{{with .Conclusion}}: {{.}}{{end}}{{end}}`,
+}
+
+// markdownTemplates are the default templates of FormatMarkdown
+var markdownTemplates = map[gitea.HookEventType]string{
+	gitea.HookEventPush: `{{repoLink .Repo}}: **{{md (user .Pusher)}}** pushed {{plural (commitCount .) "commit"}} to ` +
+		`{{with .Repo}}{{link (refName $.Ref) (refURL . $.Ref)}}{{else}}{{md (refName .Ref)}}{{end}}` +
+		`{{with .CompareURL}} ([compare]({{.}})){{end}}{{range .Commits}}
+- {{link (shortSHA .ID) .URL}} {{md (firstLine .Message)}}{{end}}`,
+	gitea.HookEventCreate: `{{repoLink .Repo}}: **{{md (user .Sender)}}** created {{.RefType}} ` + "`{{refName .Ref}}`",
+	gitea.HookEventDelete: `{{repoLink .Repo}}: **{{md (user .Sender)}}** deleted {{.RefType}} ` + "`{{refName .Ref}}`",
+	gitea.HookEventFork:   `{{repoLink .Repo}}: **{{md (user .Sender)}}** forked the repository to {{repoLink .Forkee}}`,
+	gitea.HookEventIssues: `{{repoLink .Repository}}: **{{md (user .Sender)}}** {{issueAction .Action}} issue ` +
+		`{{with .Issue}}{{link (print "#" .Index ": " .Title) .HTMLURL}}{{if eq $.Action "opened"}}{{with .Body}}
+
+{{truncate 300 .}}{{end}}{{end}}{{end}}`,
+	gitea.HookEventIssueComment: `{{repoLink .Repository}}: **{{md (user .Sender)}}** {{.Action}} a comment on ` +
+		`{{if .IsPull}}pull request{{else}}issue{{end}}{{with .Issue}} {{link (print "#" .Index ": " .Title) .HTMLURL}}{{end}}` +
+		`{{if ne .Action "deleted"}}{{with .Comment}}
+
+{{truncate 300 .Body}}{{end}}{{end}}`,
+	gitea.HookEventPullRequest: `{{repoLink .Repository}}: **{{md (user .Sender)}}** {{pullAction .}} pull request ` +
+		`{{with .PullRequest}}{{link (print "#" .Index ": " .Title) .HTMLURL}}{{end}}{{with .Review}}{{with .Content}}
+
+{{truncate 300 .}}{{end}}{{end}}`,
+	gitea.HookEventRelease: `{{repoLink .Repository}}: **{{md (user .Sender)}}** {{.Action}} release ` +
+		`{{with .Release}}{{link (or .Title .TagName) .HTMLURL}}{{end}}`,
+	gitea.HookEventRepository: `**{{md (user .Sender)}}** {{.Action}} repository {{repoLink .Repository}}`,
+	gitea.HookEventWiki: `{{repoLink .Repository}}: **{{md (user .Sender)}}** {{.Action}} wiki page ` +
+		`{{with .Repository}}{{link $.Page (wikiURL . $.Page)}}{{else}}{{md .Page}}{{end}}{{with .Comment}}: {{md .}}{{end}}`,
+	gitea.HookEventPackage: `**{{md (user .Sender)}}** {{.Action}} package {{with .Package}}**{{md .Name}}** {{md .Version}} ({{.Type}}){{end}}`,
+	gitea.HookEventStatus: `{{repoLink .Repo}}: {{link .Context .TargetURL}} is **{{.State}}** on ` +
+		`{{with .Commit}}{{link (shortSHA .ID) .URL}}{{else}}{{shortSHA .SHA}}{{end}}{{with .Description}}: {{md .}}{{end}}`,
+	gitea.HookEventWorkflowRun: `{{repoLink .Repo}}: workflow run {{with .WorkflowRun}}{{link (print "#" .RunNumber " " .DisplayTitle) .HTMLURL}}{{end}} ` +
+		`{{.Action}}{{with .WorkflowRun}}{{with .Conclusion}}: **{{.}}**{{end}}{{end}}`,
+	gitea.HookEventWorkflowJob: `{{repoLink .Repo}}: job {{with .WorkflowJob}}{{link .Name .HTMLURL}}{{end}} ` +
+		`{{.Action}}{{with .WorkflowJob}}{{with .Conclusion}}: **{{.}}**{{end}}{{end}}`,
+}
@@ -24,17 +24,14 @@ func (c *Client) AddIssueSubscription(owner, repo string, index int64, user stri
 	if err := escapeValidatePathSegments(&owner, &repo, &user); err != nil {
 		return nil, err
 	}
-	status, resp, err := c.getStatusCode("PUT", fmt.Sprintf("/repos/%s/%s/issues/%d/subscriptions/%s", owner, repo, index, user), nil, nil)
+	_, resp, err := c.getResponse("PUT", fmt.Sprintf("/repos/%s/%s/issues/%d/subscriptions/%s", owner, repo, index, user), nil, nil)
 	if err != nil {
 		return resp, err
 	}
-	if status == http.StatusCreated {
-		return resp, nil
-	}
-	if status == http.StatusOK {
+	if resp.StatusCode == http.StatusOK {
 		return resp, fmt.Errorf("already subscribed")
 	}
-	return resp, fmt.Errorf("unexpected Status: %d", status)
+	return resp, nil
 }
 
 // DeleteIssueSubscription unsubscribe user from issue
@@ -42,17 +39,14 @@ func (c *Client) DeleteIssueSubscription(owner, repo string, index int64, user s
 	if err := escapeValidatePathSegments(&owner, &repo, &user); err != nil {
 		return nil, err
 	}
-	status, resp, err := c.getStatusCode("DELETE", fmt.Sprintf("/repos/%s/%s/issues/%d/subscriptions/%s", owner, repo, index, user), nil, nil)
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/repos/%s/%s/issues/%d/subscriptions/%s", owner, repo, index, user), nil, nil)
 	if err != nil {
 		return resp, err
 	}
-	if status == http.StatusCreated {
-		return resp, nil
-	}
-	if status == http.StatusOK {
+	if resp.StatusCode == http.StatusOK {
 		return resp, fmt.Errorf("already unsubscribed")
 	}
-	return resp, fmt.Errorf("unexpected Status: %d", status)
+	return resp, nil
 }
 
 // CheckIssueSubscription check if current user is subscribed to an issue
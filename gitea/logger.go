@@ -0,0 +1,191 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// debugLogBodyLimit is the body size logged by SetDebugMode
+const debugLogBodyLimit = 64 * 1024
+
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are never logged with their value
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Gitea-Otp",
+	"Sudo",
+	"Signature",
+	"X-Ssh-Certificate",
+}
+
+// sensitiveQueryParams are never logged with their value
+var sensitiveQueryParams = []string{"token", "access_token", "sudo"}
+
+// SetLogger is an option for NewClient to log every request and response as structured event.
+// Events are logged at debug level, failed requests at warn level.
+func SetLogger(logger *slog.Logger) ClientOption {
+	return func(client *Client) error {
+		client.SetLogger(logger)
+		return nil
+	}
+}
+
+// SetLogger sets the logger used to log requests and responses, use nil to disable logging.
+// Credentials like tokens, basic auth, OTP, sudo and http signature headers are redacted.
+func (c *Client) SetLogger(logger *slog.Logger) {
+	c.mutex.Lock()
+	c.logger = logger
+	c.mutex.Unlock()
+}
+
+// SetLogBody is an option for NewClient to also log request and response bodies,
+// truncated to limit bytes. It only has an effect in combination with SetLogger.
+func SetLogBody(limit int) ClientOption {
+	return func(client *Client) error {
+		client.mutex.Lock()
+		client.logBodyLimit = limit
+		client.mutex.Unlock()
+		return nil
+	}
+}
+
+// SetDebugMode is an option for NewClient to enable debug mode,
+// which logs all requests and responses including their bodies to stdout
+func SetDebugMode() ClientOption {
+	return func(client *Client) error {
+		client.mutex.Lock()
+		client.logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		client.logBodyLimit = debugLogBodyLimit
+		client.mutex.Unlock()
+		return nil
+	}
+}
+
+// requestLogger logs requests and responses of one api call
+type requestLogger struct {
+	logger    *slog.Logger
+	bodyLimit int
+}
+
+func (l requestLogger) enabled(ctx context.Context) bool {
+	return l.logger != nil && l.logger.Enabled(ctx, slog.LevelDebug)
+}
+
+func (l requestLogger) logBodies(ctx context.Context) bool {
+	return l.bodyLimit > 0 && l.enabled(ctx)
+}
+
+// logRequest logs a request just before it is sent
+func (l requestLogger) logRequest(req *http.Request, body []byte, attempt int) {
+	ctx := req.Context()
+	if !l.enabled(ctx) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL)),
+		slog.Int("attempt", attempt),
+		slog.Int64("bytes", req.ContentLength),
+		slog.Any("header", redactHeader(req.Header)),
+	}
+	if l.logBodies(ctx) && body != nil {
+		attrs = append(attrs, slog.String("body", truncateBody(body, l.bodyLimit)))
+	}
+	l.logger.LogAttrs(ctx, slog.LevelDebug, "gitea request", attrs...)
+}
+
+// logResponse logs the outcome of a request. If bodies are logged, the
+// beginning of the response body is read and put back in front of the stream.
+func (l requestLogger) logResponse(req *http.Request, resp *http.Response, err error, duration time.Duration, attempt int) {
+	ctx := req.Context()
+	if l.logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL)),
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", duration),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		l.logger.LogAttrs(ctx, slog.LevelWarn, "gitea request failed", attrs...)
+		return
+	}
+
+	level := slog.LevelDebug
+	if resp.StatusCode >= http.StatusInternalServerError {
+		level = slog.LevelWarn
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs = append(attrs,
+		slog.Int("status", resp.StatusCode),
+		slog.Int64("bytes", resp.ContentLength),
+	)
+	if id := resp.Header.Get("X-Request-Id"); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if l.logBodies(ctx) && resp.Body != nil {
+		peek, _ := io.ReadAll(io.LimitReader(resp.Body, int64(l.bodyLimit)+1))
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(peek), resp.Body), resp.Body}
+		attrs = append(attrs, slog.String("body", truncateBody(peek, l.bodyLimit)))
+	}
+	l.logger.LogAttrs(ctx, level, "gitea response", attrs...)
+}
+
+// readCloser combines a reader with the closer of another stream
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func truncateBody(body []byte, limit int) string {
+	if len(body) > limit {
+		return string(body[:limit]) + "...(truncated)"
+	}
+	return string(body)
+}
+
+func redactHeader(header http.Header) http.Header {
+	h := header.Clone()
+	for _, key := range sensitiveHeaders {
+		if _, ok := h[key]; ok {
+			h[key] = []string{redacted}
+		}
+	}
+	return h
+}
+
+func redactURL(u *url.URL) string {
+	if u.User == nil && u.RawQuery == "" {
+		return u.String()
+	}
+	c := *u
+	if c.User != nil {
+		c.User = url.User(redacted)
+	}
+	query := c.Query()
+	for _, key := range sensitiveQueryParams {
+		if query.Has(key) {
+			query.Set(key, redacted)
+		}
+	}
+	c.RawQuery = query.Encode()
+	return c.String()
+}
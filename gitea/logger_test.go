@@ -0,0 +1,49 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLogger(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		_, _ = w.Write([]byte(`{"id": 42, "name": "test"}`))
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c, err := NewClient(server.URL,
+		SetGiteaVersion(""),
+		SetToken("s3cr3t-token"),
+		SetOTP("123456"),
+		SetSudo("admin"),
+		SetLogger(logger),
+		SetLogBody(10),
+	)
+	assert.NoError(t, err)
+
+	repo, _, err := c.GetRepo("owner", "repo")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 42, repo.ID, "logging the body must not consume it")
+
+	logs := out.String()
+	assert.Contains(t, logs, `"msg":"gitea request"`)
+	assert.Contains(t, logs, `"msg":"gitea response"`)
+	assert.Contains(t, logs, `"status":200`)
+	assert.Contains(t, logs, `"request_id":"req-1"`)
+	assert.Contains(t, logs, `"body":"{\"id\": 42,...(truncated)"`)
+	assert.NotContains(t, logs, "s3cr3t-token")
+	assert.NotContains(t, logs, "123456")
+	assert.NotContains(t, logs, `"admin"`)
+}
@@ -0,0 +1,65 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"net/http"
+	"time"
+)
+
+// RoundTripFunc sends a request and returns its response
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps the sending of requests, to observe or modify requests and responses.
+//
+// Middlewares are called once per attempt, so retried requests pass them again.
+// When a middleware is called, the request already carries the token, basic auth,
+// OTP, Sudo and User-Agent headers as well as the headers of the endpoint and the
+// conditional headers of the cache. After the last middleware the request is signed
+// (see UseSSHCert and UseSSHPubkey), logged, rate limited and sent by the http.Client.
+// Responses of clients with middlewares are only cached if SetCacheKeyFunc is used.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// UseMiddleware is an option for NewClient to add middlewares,
+// the first middleware given is the outermost one
+func UseMiddleware(middlewares ...Middleware) ClientOption {
+	return func(client *Client) error {
+		client.UseMiddleware(middlewares...)
+		return nil
+	}
+}
+
+// UseMiddleware appends middlewares to the chain of middlewares every request passes
+func (c *Client) UseMiddleware(middlewares ...Middleware) {
+	c.mutex.Lock()
+	// copy, so clients created by WithContext do not share appends
+	chain := make([]Middleware, 0, len(c.middlewares)+len(middlewares))
+	c.middlewares = append(append(chain, c.middlewares...), middlewares...)
+	c.mutex.Unlock()
+}
+
+// chainMiddlewares wraps rt with the middlewares, the first one being the outermost
+func chainMiddlewares(middlewares []Middleware, rt RoundTripFunc) RoundTripFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+// transport returns the innermost RoundTripFunc, which signs, logs, rate limits and sends a request
+func (c *Client) transport(client *http.Client, limiter *rateLimiter, logger requestLogger, sign bool, body []byte, attempt int) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		if sign && c.httpsigner != nil {
+			if err := c.SignRequest(req); err != nil {
+				return nil, err
+			}
+		}
+		logger.logRequest(req, body, attempt)
+		start := time.Now()
+		resp, err := send(client, limiter, req)
+		logger.logResponse(req, resp, err, time.Since(start), attempt)
+		return resp, err
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseMiddleware(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token abc", r.Header.Get("Authorization"))
+		assert.Equal(t, "trace-1", r.Header.Get("X-Trace-Id"))
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	tracing := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			order = append(order, "tracing")
+			assert.Equal(t, "token abc", req.Header.Get("Authorization"), "auth is applied before middlewares")
+			req.Header.Set("X-Trace-Id", "trace-1")
+			return next(req)
+		}
+	}
+	failures := 1
+	faultInjection := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			order = append(order, "fault")
+			if failures > 0 {
+				failures--
+				return &http.Response{
+					StatusCode: http.StatusServiceUnavailable,
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader("")),
+					Request:    req,
+				}, nil
+			}
+			return next(req)
+		}
+	}
+
+	c, err := NewClient(server.URL,
+		SetGiteaVersion(""),
+		SetToken("abc"),
+		SetRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		UseMiddleware(tracing, faultInjection),
+	)
+	assert.NoError(t, err)
+
+	_, resp, err := c.GetRepo("owner", "repo")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"tracing", "fault", "tracing", "fault"}, order)
+}
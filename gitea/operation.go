@@ -0,0 +1,100 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"unicode"
+)
+
+// Operation describes one logical call of the SDK, e.g. a call of GetPullRequest,
+// which may consist of several attempts if a RetryPolicy is set
+type Operation struct {
+	// Name is the name of the Client method making the request, e.g. "GetPullRequest".
+	// Methods calling other methods, like DispatchWorkflow, consist of several operations.
+	Name string
+	// Method is the http method of the request
+	Method string
+	// Path is the path of the request, relative to the api root, including the query
+	Path string
+}
+
+// CallHook is called before the first attempt of an operation. The returned context is used
+// for all requests of the operation, the returned function is called after the last attempt.
+// The error passed to it is only set if no response was received, API errors have to be
+// detected by the status code of the response.
+type CallHook func(ctx context.Context, op Operation) (context.Context, func(resp *Response, err error))
+
+// UseCallHook is an option for NewClient to add hooks observing every operation,
+// e.g. to trace or measure calls. The first hook given is the outermost one.
+func UseCallHook(hooks ...CallHook) ClientOption {
+	return func(client *Client) error {
+		client.mutex.Lock()
+		// copy, so clients created by WithContext do not share appends
+		chain := make([]CallHook, 0, len(client.callHooks)+len(hooks))
+		client.callHooks = append(append(chain, client.callHooks...), hooks...)
+		client.mutex.Unlock()
+		return nil
+	}
+}
+
+type operationKey struct{}
+
+// OperationFromContext returns the operation a request belongs to,
+// it can be used by middlewares with the context of the request
+func OperationFromContext(ctx context.Context) (Operation, bool) {
+	op, ok := ctx.Value(operationKey{}).(Operation)
+	return op, ok
+}
+
+// startOperation runs the call hooks and returns the context for the requests of the operation
+// and a function to be called with the final result
+func startOperation(ctx context.Context, hooks []CallHook, method, path string) (context.Context, func(*Response, error)) {
+	if len(hooks) == 0 {
+		return ctx, func(*Response, error) {}
+	}
+	op := Operation{Name: operationName(), Method: method, Path: path}
+	ctx = context.WithValue(ctx, operationKey{}, op)
+	ends := make([]func(*Response, error), 0, len(hooks))
+	for _, hook := range hooks {
+		var end func(*Response, error)
+		ctx, end = hook(ctx, op)
+		if end != nil {
+			ends = append(ends, end)
+		}
+	}
+	return ctx, func(resp *Response, err error) {
+		for i := len(ends) - 1; i >= 0; i-- {
+			ends[i](resp, err)
+		}
+	}
+}
+
+const clientMethodPrefix = "code.gitea.io/sdk/gitea.(*Client)."
+
+// operationName returns the name of the innermost exported Client method on the call stack,
+// so requests a method makes through other methods, e.g. the lookup of the server version,
+// are named after the method making them
+func operationName() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "code.gitea.io/sdk/gitea.") {
+			break
+		}
+		if method, ok := strings.CutPrefix(frame.Function, clientMethodPrefix); ok &&
+			!strings.Contains(method, ".") && method != "" && unicode.IsUpper(rune(method[0])) {
+			return method
+		}
+		if !more {
+			break
+		}
+	}
+	return "Unknown"
+}
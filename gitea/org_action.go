@@ -8,7 +8,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 )
 
@@ -67,21 +66,141 @@ func (c *Client) CreateOrgActionSecret(org string, opt CreateSecretOption) (*Res
 		return nil, err
 	}
 
-	status, resp, err := c.getStatusCode("PUT", fmt.Sprintf("/orgs/%s/actions/secrets/%s", org, opt.Name), jsonHeader, bytes.NewReader(body))
+	_, resp, err := c.getResponse("PUT", fmt.Sprintf("/orgs/%s/actions/secrets/%s", org, opt.Name), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
+
+// DeleteOrgActionSecret deletes a secret of the specified organization in the Gitea Actions.
+func (c *Client) DeleteOrgActionSecret(org, secretName string) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&org, &secretName); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/orgs/%s/actions/secrets/%s", org, secretName), jsonHeader, nil)
+	return resp, err
+}
+
+// ListOrgActionVariableOption list OrgActionVariable options
+type ListOrgActionVariableOption struct {
+	ListOptions
+}
+
+// ListOrgActionVariable list an organization's variables
+func (c *Client) ListOrgActionVariable(org string, opt ListOrgActionVariableOption) ([]*Variable, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&org); err != nil {
+		return nil, nil, err
+	}
+	opt.setDefaults()
+	variables := make([]*Variable, 0, opt.PageSize)
+
+	link, _ := url.Parse(fmt.Sprintf("/orgs/%s/actions/variables", org))
+	link.RawQuery = opt.getURLQuery().Encode()
+	resp, err := c.getParsedResponse("GET", link.String(), jsonHeader, nil, &variables)
+	return variables, resp, err
+}
+
+// GetOrgActionVariable get a variable of an organization
+func (c *Client) GetOrgActionVariable(org, name string) (*Variable, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&org, &name); err != nil {
+		return nil, nil, err
+	}
+	variable := new(Variable)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("/orgs/%s/actions/variables/%s", org, name), jsonHeader, nil, variable)
+	return variable, resp, err
+}
+
+// CreateOrgActionVariable creates a variable for the specified organization in the Gitea Actions
+func (c *Client) CreateOrgActionVariable(org string, opt CreateVariableOption) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := (&opt).Validate(); err != nil {
+		return nil, err
+	}
+	name := opt.Name
+	if err := escapeValidatePathSegments(&org, &name); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&opt)
+	if err != nil {
+		return nil, err
+	}
+
+	_, resp, err := c.getResponse("POST", fmt.Sprintf("/orgs/%s/actions/variables/%s", org, name), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
+
+// UpdateOrgActionVariable updates the value, and optionally the name, of a variable of an organization
+func (c *Client) UpdateOrgActionVariable(org, name string, opt UpdateVariableOption) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := (&opt).Validate(); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&org, &name); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&opt)
 	if err != nil {
 		return nil, err
 	}
 
-	switch status {
-	case http.StatusCreated:
-		return resp, nil
-	case http.StatusNoContent:
-		return resp, nil
-	case http.StatusNotFound:
-		return resp, fmt.Errorf("forbidden")
-	case http.StatusBadRequest:
-		return resp, fmt.Errorf("bad request")
-	default:
-		return resp, fmt.Errorf("unexpected Status: %d", status)
+	_, resp, err := c.getResponse("PUT", fmt.Sprintf("/orgs/%s/actions/variables/%s", org, name), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
+
+// DeleteOrgActionVariable deletes a variable of an organization
+func (c *Client) DeleteOrgActionVariable(org, name string) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&org, &name); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/orgs/%s/actions/variables/%s", org, name), jsonHeader, nil)
+	return resp, err
+}
+
+// GetOrgRunnerRegistrationToken gets a token to register a runner of an organization
+func (c *Client) GetOrgRunnerRegistrationToken(org string) (*RegistrationToken, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_21_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&org); err != nil {
+		return nil, nil, err
+	}
+	return c.getRegistrationToken(fmt.Sprintf("/orgs/%s/actions/runners/registration-token", org))
+}
+
+// ListOrgActionRunners lists the runners of an organization
+func (c *Client) ListOrgActionRunners(org string, opt ListActionRunnersOptions) (*ActionRunnersResponse, *Response, error) {
+	if err := escapeValidatePathSegments(&org); err != nil {
+		return nil, nil, err
+	}
+	return c.listActionRunners(fmt.Sprintf("/orgs/%s/actions/runners", org), opt)
+}
+
+// GetOrgActionRunner gets a runner of an organization
+func (c *Client) GetOrgActionRunner(org string, runnerID int64) (*ActionRunner, *Response, error) {
+	if err := escapeValidatePathSegments(&org); err != nil {
+		return nil, nil, err
+	}
+	return c.getActionRunner(fmt.Sprintf("/orgs/%s/actions/runners", org), runnerID)
+}
+
+// DeleteOrgActionRunner deletes a runner of an organization
+func (c *Client) DeleteOrgActionRunner(org string, runnerID int64) (*Response, error) {
+	if err := escapeValidatePathSegments(&org); err != nil {
+		return nil, err
 	}
+	return c.deleteActionRunner(fmt.Sprintf("/orgs/%s/actions/runners", org), runnerID)
 }
@@ -36,4 +36,11 @@ func TestCreateOrgActionSecret(t *testing.T) {
 	secrets, _, err := c.ListOrgActionSecret(newOrg.UserName, ListOrgActionSecretOption{})
 	assert.NoError(t, err)
 	assert.Len(t, secrets, 1)
+
+	// delete secret
+	resp, err = c.DeleteOrgActionSecret(newOrg.UserName, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	_, err = c.DeleteOrgActionSecret(newOrg.UserName, "test")
+	assert.ErrorIs(t, err, ErrNotFound)
 }
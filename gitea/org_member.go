@@ -5,8 +5,8 @@
 package gitea
 
 import (
+	"errors"
 	"fmt"
-	"net/http"
 	"net/url"
 )
 
@@ -57,18 +57,14 @@ func (c *Client) CheckOrgMembership(org, user string) (bool, *Response, error) {
 	if err := escapeValidatePathSegments(&org, &user); err != nil {
 		return false, nil, err
 	}
-	status, resp, err := c.getStatusCode("GET", fmt.Sprintf("/orgs/%s/members/%s", org, user), nil, nil)
+	_, resp, err := c.getResponse("GET", fmt.Sprintf("/orgs/%s/members/%s", org, user), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, resp, nil
+	}
 	if err != nil {
 		return false, resp, err
 	}
-	switch status {
-	case http.StatusNoContent:
-		return true, resp, nil
-	case http.StatusNotFound:
-		return false, resp, nil
-	default:
-		return false, resp, fmt.Errorf("unexpected Status: %d", status)
-	}
+	return true, resp, nil
 }
 
 // CheckPublicOrgMembership Check if a user is a member of an organization
@@ -76,18 +72,14 @@ func (c *Client) CheckPublicOrgMembership(org, user string) (bool, *Response, er
 	if err := escapeValidatePathSegments(&org, &user); err != nil {
 		return false, nil, err
 	}
-	status, resp, err := c.getStatusCode("GET", fmt.Sprintf("/orgs/%s/public_members/%s", org, user), nil, nil)
+	_, resp, err := c.getResponse("GET", fmt.Sprintf("/orgs/%s/public_members/%s", org, user), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, resp, nil
+	}
 	if err != nil {
 		return false, resp, err
 	}
-	switch status {
-	case http.StatusNoContent:
-		return true, resp, nil
-	case http.StatusNotFound:
-		return false, resp, nil
-	default:
-		return false, resp, fmt.Errorf("unexpected Status: %d", status)
-	}
+	return true, resp, nil
 }
 
 // SetPublicOrgMembership publicize/conceal a user's membership
@@ -95,27 +87,12 @@ func (c *Client) SetPublicOrgMembership(org, user string, visible bool) (*Respon
 	if err := escapeValidatePathSegments(&org, &user); err != nil {
 		return nil, err
 	}
-	var (
-		status int
-		err    error
-		resp   *Response
-	)
+	method := "DELETE"
 	if visible {
-		status, resp, err = c.getStatusCode("PUT", fmt.Sprintf("/orgs/%s/public_members/%s", org, user), nil, nil)
-	} else {
-		status, resp, err = c.getStatusCode("DELETE", fmt.Sprintf("/orgs/%s/public_members/%s", org, user), nil, nil)
-	}
-	if err != nil {
-		return resp, err
-	}
-	switch status {
-	case http.StatusNoContent:
-		return resp, nil
-	case http.StatusNotFound:
-		return resp, fmt.Errorf("forbidden")
-	default:
-		return resp, fmt.Errorf("unexpected Status: %d", status)
+		method = "PUT"
 	}
+	_, resp, err := c.getResponse(method, fmt.Sprintf("/orgs/%s/public_members/%s", org, user), nil, nil)
+	return resp, err
 }
 
 // OrgPermissions represents the permissions for an user in an organization
@@ -0,0 +1,212 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package otel instruments a gitea.Client with OpenTelemetry tracing and metrics.
+//
+//	client, err := gitea.NewClient(url, gitea.SetToken(token), otel.Instrument())
+//
+// Every request of the SDK creates a client span named after the method making it,
+// e.g. "gitea.GetPullRequest", and is recorded in the metrics
+// "gitea.client.requests" and "gitea.client.duration". Methods calling other methods
+// create a span per request, e.g. the lazy lookup of the server version is named "gitea.ServerVersion".
+//
+// The instrumentation adds a middleware to the client, use it together with gitea.SetCache
+// and gitea.SetCacheKeyFunc returning "", as it does not change the authentication of requests.
+//
+// The package is a module of its own, so the OpenTelemetry dependencies are only
+// required by programs using it.
+package otel // import "code.gitea.io/sdk/gitea/otel"
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+
+	gotel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "code.gitea.io/sdk/gitea/otel"
+
+// Option configures the instrumentation
+type Option func(*config)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+}
+
+// WithTracerProvider sets the TracerProvider, by default the global one is used
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider sets the MeterProvider, by default the global one is used
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = provider
+	}
+}
+
+// WithPropagators sets the propagators used to send the trace context to the server,
+// by default the global ones are used
+func WithPropagators(propagators propagation.TextMapPropagator) Option {
+	return func(c *config) {
+		c.propagators = propagators
+	}
+}
+
+// Instrument is an option for gitea.NewClient to trace and measure all calls of the client
+func Instrument(opts ...Option) gitea.ClientOption {
+	cfg := config{
+		tracerProvider: gotel.GetTracerProvider(),
+		meterProvider:  gotel.GetMeterProvider(),
+		propagators:    gotel.GetTextMapPropagator(),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(client *gitea.Client) error {
+		inst, err := newInstrumentation(cfg)
+		if err != nil {
+			return err
+		}
+		if err := gitea.UseCallHook(inst.callHook)(client); err != nil {
+			return err
+		}
+		return gitea.UseMiddleware(inst.middleware)(client)
+	}
+}
+
+type instrumentation struct {
+	tracer      trace.Tracer
+	propagators propagation.TextMapPropagator
+	requests    metric.Int64Counter
+	duration    metric.Float64Histogram
+}
+
+func newInstrumentation(cfg config) (*instrumentation, error) {
+	meter := cfg.meterProvider.Meter(instrumentationName, metric.WithInstrumentationVersion(gitea.Version()))
+	requests, err := meter.Int64Counter("gitea.client.requests",
+		metric.WithDescription("Number of calls of the gitea SDK"),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("gitea.client.duration",
+		metric.WithDescription("Duration of calls of the gitea SDK, including retries"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentation{
+		tracer:      cfg.tracerProvider.Tracer(instrumentationName, trace.WithInstrumentationVersion(gitea.Version())),
+		propagators: cfg.propagators,
+		requests:    requests,
+		duration:    duration,
+	}, nil
+}
+
+type attemptsKey struct{}
+
+// callHook starts a span for every operation and records the metrics once it is done
+func (i *instrumentation) callHook(ctx context.Context, op gitea.Operation) (context.Context, func(*gitea.Response, error)) {
+	path, _, _ := strings.Cut(op.Path, "?")
+	attrs := append([]attribute.KeyValue{
+		attribute.String("gitea.operation", op.Name),
+		attribute.String("http.request.method", op.Method),
+		attribute.String("url.path", path),
+	}, pathAttributes(path)...)
+
+	start := time.Now()
+	attempts := new(atomic.Int64)
+	ctx = context.WithValue(ctx, attemptsKey{}, attempts)
+	ctx, span := i.tracer.Start(ctx, "gitea."+op.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	return ctx, func(resp *gitea.Response, err error) {
+		status := 0
+		if resp != nil && resp.Response != nil {
+			status = resp.StatusCode
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if resp.CacheHit {
+				span.SetAttributes(attribute.Bool("gitea.cache_hit", true))
+			}
+		}
+		if n := attempts.Load(); n > 1 {
+			span.SetAttributes(attribute.Int64("gitea.retries", n-1))
+		}
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		case status >= 400:
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+
+		metricAttrs := metric.WithAttributes(
+			attribute.String("gitea.operation", op.Name),
+			attribute.String("http.request.method", op.Method),
+			attribute.Int("http.response.status_code", status),
+		)
+		i.requests.Add(ctx, 1, metricAttrs)
+		i.duration.Record(ctx, time.Since(start).Seconds(), metricAttrs)
+	}
+}
+
+// middleware counts the attempts of an operation and propagates the trace context to the server
+func (i *instrumentation) middleware(next gitea.RoundTripFunc) gitea.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		if attempts, ok := ctx.Value(attemptsKey{}).(*atomic.Int64); ok {
+			if n := attempts.Add(1); n > 1 {
+				trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(attribute.Int64("gitea.attempt", n)))
+			}
+		}
+		i.propagators.Inject(ctx, propagation.HeaderCarrier(req.Header))
+		return next(req)
+	}
+}
+
+// pathAttributes extracts owner, repo and index of issues and pull requests from an api path
+func pathAttributes(path string) []attribute.KeyValue {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for n := range segments {
+		if s, err := url.PathUnescape(segments[n]); err == nil {
+			segments[n] = s
+		}
+	}
+
+	var attrs []attribute.KeyValue
+	switch {
+	case len(segments) >= 3 && segments[0] == "repos":
+		attrs = append(attrs,
+			attribute.String("gitea.owner", segments[1]),
+			attribute.String("gitea.repo", segments[2]))
+		if len(segments) >= 5 && (segments[3] == "issues" || segments[3] == "pulls") {
+			if index, err := strconv.ParseInt(segments[4], 10, 64); err == nil {
+				attrs = append(attrs, attribute.Int64("gitea.index", index))
+			}
+		}
+	case len(segments) >= 2 && (segments[0] == "orgs" || segments[0] == "users"):
+		attrs = append(attrs, attribute.String("gitea.owner", segments[1]))
+	}
+	return attrs
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInstrument(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("Traceparent"))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"number": 7}`))
+	}))
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	c, err := gitea.NewClient(server.URL,
+		gitea.SetGiteaVersion(""),
+		gitea.SetRetryPolicy(gitea.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+		Instrument(
+			WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+			WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+			WithPropagators(propagation.TraceContext{}),
+		),
+	)
+	assert.NoError(t, err)
+
+	pr, _, err := c.GetPullRequest("owner", "repo", 7)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7, pr.Index)
+
+	ended := spans.Ended()
+	if assert.Len(t, ended, 1) {
+		span := ended[0]
+		assert.Equal(t, "gitea.GetPullRequest", span.Name())
+		assert.Equal(t, codes.Unset, span.Status().Code)
+		attrs := attribute.NewSet(span.Attributes()...)
+		for key, expected := range map[attribute.Key]attribute.Value{
+			"gitea.owner":               attribute.StringValue("owner"),
+			"gitea.repo":                attribute.StringValue("repo"),
+			"gitea.index":               attribute.Int64Value(7),
+			"gitea.retries":             attribute.Int64Value(1),
+			"http.response.status_code": attribute.IntValue(http.StatusOK),
+		} {
+			value, ok := attrs.Value(key)
+			assert.True(t, ok, "attribute %s missing", key)
+			assert.Equal(t, expected, value, "attribute %s", key)
+		}
+		assert.Len(t, span.Events(), 1)
+	}
+
+	var rm metricdata.ResourceMetrics
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+	names := map[string]bool{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names[m.Name] = true
+		}
+	}
+	assert.True(t, names["gitea.client.requests"])
+	assert.True(t, names["gitea.client.duration"])
+}
+
+func TestInstrumentNestedCalls(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/version", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version": "1.24.0"}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/workflows/deploy.yml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "deploy.yml", "path": ".gitea/workflows/deploy.yml"}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/acme/api/raw/.gitea/workflows/deploy.yml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("on: workflow_dispatch\n"))
+	})
+	mux.HandleFunc("POST /api/v1/repos/acme/api/actions/workflows/deploy.yml/dispatches", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	spans := tracetest.NewSpanRecorder()
+	c, err := gitea.NewClient(server.URL, Instrument(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))),
+	))
+	assert.NoError(t, err)
+	_, err = c.DispatchWorkflow("acme", "api", "deploy.yml", "main", nil)
+	assert.NoError(t, err)
+
+	// every request is named after the innermost method making it
+	var names []string
+	for _, span := range spans.Ended() {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{
+		"gitea.ServerVersion",
+		"gitea.GetRepoActionWorkflow",
+		"gitea.GetFileReader",
+		"gitea.DispatchWorkflow",
+	}, names)
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"iter"
+	"strconv"
+)
+
+// PageFunc fetches one page of a list endpoint, e.g.
+//
+//	func(opt gitea.ListOptions) ([]*gitea.Issue, *gitea.Response, error) {
+//		return c.ListRepoIssues(owner, repo, gitea.ListIssueOption{ListOptions: opt})
+//	}
+//
+// The function may change opt.PageSize before using it, the page is set by the iterator.
+type PageFunc[T any] func(opt ListOptions) ([]T, *Response, error)
+
+// Paginate returns an iterator over all items of a list endpoint, fetching
+// page after page until the last one, the context is canceled or an error occurs.
+// Errors are yielded together with the zero value of T and end the iteration.
+func Paginate[T any](ctx context.Context, fetch PageFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		var fetched int64
+		page := 1
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			opt := ListOptions{Page: page}
+			items, resp, err := fetch(opt)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			fetched += int64(len(items))
+
+			next, ok := nextPage(resp, page, len(items), fetched)
+			if !ok {
+				return
+			}
+			page = next
+		}
+	}
+}
+
+// CollectAll collects the items of all pages of a list endpoint.
+// If limit is greater than zero, it stops after limit items.
+func CollectAll[T any](ctx context.Context, fetch PageFunc[T], limit int) ([]T, error) {
+	var all []T
+	for item, err := range Paginate(ctx, fetch) {
+		if err != nil {
+			return all, err
+		}
+		all = append(all, item)
+		if limit > 0 && len(all) >= limit {
+			break
+		}
+	}
+	return all, nil
+}
+
+// nextPage determines the page to fetch after the current one, based on the
+// X-Total-Count and Link headers of the response
+func nextPage(resp *Response, page, count int, fetched int64) (int, bool) {
+	if count == 0 {
+		return 0, false
+	}
+	if resp == nil || resp.Response == nil {
+		return page + 1, true
+	}
+	if total, ok := totalCount(resp); ok && fetched >= total {
+		return 0, false
+	}
+	if resp.NextPage > page {
+		return resp.NextPage, true
+	}
+	if resp.Header.Get("Link") != "" {
+		// server paginates but has no next page
+		return 0, false
+	}
+	return page + 1, true
+}
+
+// totalCount returns the total number of items as reported by the X-Total-Count header
+func totalCount(resp *Response) (int64, bool) {
+	raw := resp.Header.Get("X-Total-Count")
+	if raw == "" {
+		return 0, false
+	}
+	total, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return total, true
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPaginationTestServer(t *testing.T, total, pageSize int, withHeaders bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		lastPage := (total + pageSize - 1) / pageSize
+		if withHeaders {
+			w.Header().Set("X-Total-Count", strconv.Itoa(total))
+			if page < lastPage {
+				w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=%d>; rel="next",<http://%s%s?page=%d>; rel="last"`, r.Host, r.URL.Path, page+1, r.Host, r.URL.Path, lastPage))
+			}
+		}
+		repos := "["
+		for i := (page - 1) * pageSize; i < page*pageSize && i < total; i++ {
+			if i != (page-1)*pageSize {
+				repos += ","
+			}
+			repos += fmt.Sprintf(`{"id": %d}`, i+1)
+		}
+		_, _ = w.Write([]byte(repos + "]"))
+	}))
+}
+
+func TestPaginate(t *testing.T) {
+	for _, withHeaders := range []bool{true, false} {
+		server := newPaginationTestServer(t, 7, 3, withHeaders)
+		c, err := NewClient(server.URL, SetGiteaVersion(""))
+		assert.NoError(t, err)
+
+		calls := 0
+		fetch := func(opt ListOptions) ([]*Repository, *Response, error) {
+			calls++
+			opt.PageSize = 3
+			return c.ListOrgRepos("org", ListOrgReposOptions{ListOptions: opt})
+		}
+
+		var ids []int64
+		for repo, err := range Paginate(context.Background(), fetch) {
+			assert.NoError(t, err)
+			ids = append(ids, repo.ID)
+		}
+		assert.EqualValues(t, []int64{1, 2, 3, 4, 5, 6, 7}, ids)
+		if withHeaders {
+			assert.Equal(t, 3, calls)
+		} else {
+			// without headers the end is only detected by an empty page
+			assert.Equal(t, 4, calls)
+		}
+
+		repos, err := CollectAll(context.Background(), fetch, 4)
+		assert.NoError(t, err)
+		assert.Len(t, repos, 4)
+		server.Close()
+	}
+}
+
+func TestPaginateCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fetch := func(opt ListOptions) ([]int, *Response, error) {
+		return []int{1}, nil, nil
+	}
+	repos, err := CollectAll(ctx, fetch, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, repos)
+}
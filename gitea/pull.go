@@ -8,7 +8,9 @@ package gitea
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 	"time"
@@ -245,7 +247,8 @@ func (opt MergePullRequestOption) Validate(c *Client) error {
 	return nil
 }
 
-// MergePullRequest merge a PR to repository by PR id
+// MergePullRequest merge a PR to repository by PR id,
+// if the PR can not be merged the error matches ErrConflict
 func (c *Client) MergePullRequest(owner, repo string, index int64, opt MergePullRequestOption) (bool, *Response, error) {
 	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
 		return false, nil, err
@@ -257,11 +260,16 @@ func (c *Client) MergePullRequest(owner, repo string, index int64, opt MergePull
 	if err != nil {
 		return false, nil, err
 	}
-	status, resp, err := c.getStatusCode("POST", fmt.Sprintf("/repos/%s/%s/pulls/%d/merge", owner, repo, index), jsonHeader, bytes.NewReader(body))
+	_, resp, err := c.getResponse("POST", fmt.Sprintf("/repos/%s/%s/pulls/%d/merge", owner, repo, index), jsonHeader, bytes.NewReader(body))
 	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusMethodNotAllowed {
+			// Gitea answers 405 for PRs which are not mergeable, e.g. because of conflicts
+			err = fmt.Errorf("%w: %w", ErrConflict, err)
+		}
 		return false, resp, err
 	}
-	return status == 200, resp, nil
+	return true, resp, nil
 }
 
 // IsPullRequestMerged test if one PR is merged to one repository
@@ -269,12 +277,15 @@ func (c *Client) IsPullRequestMerged(owner, repo string, index int64) (bool, *Re
 	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
 		return false, nil, err
 	}
-	status, resp, err := c.getStatusCode("GET", fmt.Sprintf("/repos/%s/%s/pulls/%d/merge", owner, repo, index), nil, nil)
+	_, resp, err := c.getResponse("GET", fmt.Sprintf("/repos/%s/%s/pulls/%d/merge", owner, repo, index), nil, nil)
 	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			// the API answers 404 for PRs which are not merged
+			return false, resp, nil
+		}
 		return false, resp, err
 	}
-
-	return status == 204, resp, nil
+	return true, resp, nil
 }
 
 // PullRequestDiffOptions options for GET /repos/<owner>/<repo>/pulls/<idx>.[diff|patch]
@@ -115,7 +115,7 @@ func TestPull(t *testing.T) {
 		Title:   "pullConflict",
 		Message: "pullConflict Msg",
 	})
-	assert.NoError(t, err)
+	assert.ErrorIs(t, err, ErrConflict)
 	assert.False(t, merged)
 	merged, _, err = c.IsPullRequestMerged(user.UserName, repoName, pullConflict.Index)
 	assert.NoError(t, err)
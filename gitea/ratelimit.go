@@ -0,0 +1,220 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimit configures client side limiting of the requests sent to the server.
+// The limits are shared by all goroutines using the same Client.
+type RateLimit struct {
+	// RequestsPerSecond is the sustained request rate, zero disables rate limiting
+	RequestsPerSecond float64
+	// Burst is the number of requests which may be sent at once, defaults to 1
+	Burst int
+	// MaxInFlight limits the number of concurrent requests, zero means unlimited.
+	// A request is in flight until its response body is closed.
+	MaxInFlight int
+}
+
+// RateLimitStats contains statistics about the client side rate limiting
+type RateLimitStats struct {
+	// Requests is the number of requests which passed the limiter
+	Requests int64
+	// Delayed is the number of requests which had to wait
+	Delayed int64
+	// WaitTime is the total time requests spent waiting
+	WaitTime time.Duration
+	// InFlight is the number of requests currently in flight
+	InFlight int
+}
+
+// SetRateLimit is an option for NewClient to limit the rate and concurrency of requests.
+// If the server sends X-RateLimit-Remaining/X-RateLimit-Reset headers or a 429 with
+// Retry-After, further requests are delayed until the server accepts them again.
+func SetRateLimit(limit RateLimit) ClientOption {
+	return func(client *Client) error {
+		client.mutex.Lock()
+		client.rateLimiter = newRateLimiter(limit)
+		client.mutex.Unlock()
+		return nil
+	}
+}
+
+// RateLimitStats returns statistics about the client side rate limiting
+func (c *Client) RateLimitStats() RateLimitStats {
+	c.mutex.RLock()
+	limiter := c.rateLimiter
+	c.mutex.RUnlock()
+	if limiter == nil {
+		return RateLimitStats{}
+	}
+	return limiter.stats()
+}
+
+// rateLimiter combines a token bucket with a semaphore
+type rateLimiter struct {
+	rate     float64
+	burst    float64
+	inFlight chan struct{}
+
+	mutex        sync.Mutex
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+
+	requests atomic.Int64
+	delayed  atomic.Int64
+	waited   atomic.Int64
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	l := &rateLimiter{
+		rate:  limit.RequestsPerSecond,
+		burst: float64(limit.Burst),
+	}
+	if l.burst < 1 {
+		l.burst = 1
+	}
+	l.tokens = l.burst
+	if limit.MaxInFlight > 0 {
+		l.inFlight = make(chan struct{}, limit.MaxInFlight)
+	}
+	return l
+}
+
+func (l *rateLimiter) stats() RateLimitStats {
+	return RateLimitStats{
+		Requests: l.requests.Load(),
+		Delayed:  l.delayed.Load(),
+		WaitTime: time.Duration(l.waited.Load()),
+		InFlight: len(l.inFlight),
+	}
+}
+
+// acquire blocks until a request may be sent, the returned function must be called once it is done
+func (l *rateLimiter) acquire(ctx context.Context) (func(), error) {
+	start := time.Now()
+	release := func() {}
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+			release = func() { <-l.inFlight }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	for {
+		wait := l.reserve(time.Now())
+		if wait <= 0 {
+			break
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	l.requests.Add(1)
+	if waited := time.Since(start); waited > time.Millisecond {
+		l.delayed.Add(1)
+		l.waited.Add(int64(waited))
+	}
+	return release, nil
+}
+
+// reserve takes a token if available, otherwise it returns how long to wait for the next one
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if now.Before(l.blockedUntil) {
+		return l.blockedUntil.Sub(now)
+	}
+	if l.rate <= 0 {
+		return 0
+	}
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// observe pauses the limiter if the server signals that its rate limit is exhausted
+func (l *rateLimiter) observe(resp *http.Response) {
+	var until time.Time
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			until = time.Now().Add(after)
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			// the reset is either a unix timestamp or a number of seconds
+			if reset > 1e9 {
+				until = time.Unix(reset, 0)
+			} else {
+				until = time.Now().Add(time.Duration(reset) * time.Second)
+			}
+		}
+	}
+	if until.IsZero() {
+		return
+	}
+
+	l.mutex.Lock()
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+	l.mutex.Unlock()
+}
+
+// releaseOnClose releases the limiter slot of a request once its body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+// send sends a request, respecting the client side rate limits if any
+func send(client *http.Client, limiter *rateLimiter, req *http.Request) (*http.Response, error) {
+	if limiter == nil {
+		return client.Do(req)
+	}
+	release, err := limiter.acquire(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	limiter.observe(resp)
+	resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	var current, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		defer atomic.AddInt32(&current, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, SetGiteaVersion(""), SetRateLimit(RateLimit{
+		RequestsPerSecond: 200,
+		Burst:             2,
+		MaxInFlight:       2,
+	}))
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := c.ListRepoBranches("owner", "repo", ListRepoBranchesOptions{})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+	stats := c.RateLimitStats()
+	assert.EqualValues(t, 10, stats.Requests)
+	assert.Positive(t, stats.Delayed)
+	assert.Positive(t, stats.WaitTime)
+	assert.Zero(t, stats.InFlight)
+}
+
+func TestRateLimitServerHeaders(t *testing.T) {
+	l := newRateLimiter(RateLimit{})
+	assert.Zero(t, l.reserve(time.Now()))
+
+	l.observe(&http.Response{StatusCode: http.StatusOK, Header: http.Header{
+		"X-Ratelimit-Remaining": []string{"0"},
+		"X-Ratelimit-Reset":     []string{"2"},
+	}})
+	wait := l.reserve(time.Now())
+	assert.Greater(t, wait, time.Second)
+	assert.LessOrEqual(t, wait, 2*time.Second)
+}
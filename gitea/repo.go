@@ -7,6 +7,7 @@ package gitea
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -168,6 +169,13 @@ func (c *Client) ListOrgRepos(org string, opt ListOrgReposOptions) ([]*Repositor
 	return repos, resp, err
 }
 
+// listAllOrgRepos returns the repositories of an organization from all pages
+func (c *Client) listAllOrgRepos(ctx context.Context, org string) ([]*Repository, error) {
+	return CollectAll(ctx, func(opt ListOptions) ([]*Repository, *Response, error) {
+		return c.ListOrgRepos(org, ListOrgReposOptions{ListOptions: opt})
+	}, 0)
+}
+
 // SearchRepoOptions options for searching repositories
 type SearchRepoOptions struct {
 	ListOptions
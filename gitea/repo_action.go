@@ -8,7 +8,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 )
 
@@ -46,21 +45,141 @@ func (c *Client) CreateRepoActionSecret(user, repo string, opt CreateSecretOptio
 		return nil, err
 	}
 
-	status, resp, err := c.getStatusCode("PUT", fmt.Sprintf("/repos/%s/%s/actions/secrets/%s", user, repo, opt.Name), jsonHeader, bytes.NewReader(body))
+	_, resp, err := c.getResponse("PUT", fmt.Sprintf("/repos/%s/%s/actions/secrets/%s", user, repo, opt.Name), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
+
+// DeleteRepoActionSecret deletes a secret of the specified repository in the Gitea Actions.
+func (c *Client) DeleteRepoActionSecret(user, repo, secretName string) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&user, &repo, &secretName); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/repos/%s/%s/actions/secrets/%s", user, repo, secretName), jsonHeader, nil)
+	return resp, err
+}
+
+// ListRepoActionVariableOption list RepoActionVariable options
+type ListRepoActionVariableOption struct {
+	ListOptions
+}
+
+// ListRepoActionVariable list a repository's variables
+func (c *Client) ListRepoActionVariable(user, repo string, opt ListRepoActionVariableOption) ([]*Variable, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&user, &repo); err != nil {
+		return nil, nil, err
+	}
+	opt.setDefaults()
+	variables := make([]*Variable, 0, opt.PageSize)
+
+	link, _ := url.Parse(fmt.Sprintf("/repos/%s/%s/actions/variables", user, repo))
+	link.RawQuery = opt.getURLQuery().Encode()
+	resp, err := c.getParsedResponse("GET", link.String(), jsonHeader, nil, &variables)
+	return variables, resp, err
+}
+
+// GetRepoActionVariable get a variable of a repository
+func (c *Client) GetRepoActionVariable(user, repo, name string) (*Variable, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&user, &repo, &name); err != nil {
+		return nil, nil, err
+	}
+	variable := new(Variable)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("/repos/%s/%s/actions/variables/%s", user, repo, name), jsonHeader, nil, variable)
+	return variable, resp, err
+}
+
+// CreateRepoActionVariable creates a variable for the specified repository in the Gitea Actions
+func (c *Client) CreateRepoActionVariable(user, repo string, opt CreateVariableOption) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := (&opt).Validate(); err != nil {
+		return nil, err
+	}
+	name := opt.Name
+	if err := escapeValidatePathSegments(&user, &repo, &name); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&opt)
+	if err != nil {
+		return nil, err
+	}
+
+	_, resp, err := c.getResponse("POST", fmt.Sprintf("/repos/%s/%s/actions/variables/%s", user, repo, name), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
+
+// UpdateRepoActionVariable updates the value, and optionally the name, of a variable of a repository
+func (c *Client) UpdateRepoActionVariable(user, repo, name string, opt UpdateVariableOption) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := (&opt).Validate(); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&user, &repo, &name); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&opt)
 	if err != nil {
 		return nil, err
 	}
 
-	switch status {
-	case http.StatusCreated:
-		return resp, nil
-	case http.StatusNoContent:
-		return resp, nil
-	case http.StatusNotFound:
-		return resp, fmt.Errorf("forbidden")
-	case http.StatusBadRequest:
-		return resp, fmt.Errorf("bad request")
-	default:
-		return resp, fmt.Errorf("unexpected Status: %d", status)
+	_, resp, err := c.getResponse("PUT", fmt.Sprintf("/repos/%s/%s/actions/variables/%s", user, repo, name), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
+
+// DeleteRepoActionVariable deletes a variable of a repository
+func (c *Client) DeleteRepoActionVariable(user, repo, name string) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&user, &repo, &name); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/repos/%s/%s/actions/variables/%s", user, repo, name), jsonHeader, nil)
+	return resp, err
+}
+
+// GetRepoRunnerRegistrationToken gets a token to register a runner of a repository
+func (c *Client) GetRepoRunnerRegistrationToken(user, repo string) (*RegistrationToken, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&user, &repo); err != nil {
+		return nil, nil, err
+	}
+	return c.getRegistrationToken(fmt.Sprintf("/repos/%s/%s/actions/runners/registration-token", user, repo))
+}
+
+// ListRepoActionRunners lists the runners of a repository
+func (c *Client) ListRepoActionRunners(user, repo string, opt ListActionRunnersOptions) (*ActionRunnersResponse, *Response, error) {
+	if err := escapeValidatePathSegments(&user, &repo); err != nil {
+		return nil, nil, err
+	}
+	return c.listActionRunners(fmt.Sprintf("/repos/%s/%s/actions/runners", user, repo), opt)
+}
+
+// GetRepoActionRunner gets a runner of a repository
+func (c *Client) GetRepoActionRunner(user, repo string, runnerID int64) (*ActionRunner, *Response, error) {
+	if err := escapeValidatePathSegments(&user, &repo); err != nil {
+		return nil, nil, err
+	}
+	return c.getActionRunner(fmt.Sprintf("/repos/%s/%s/actions/runners", user, repo), runnerID)
+}
+
+// DeleteRepoActionRunner deletes a runner of a repository
+func (c *Client) DeleteRepoActionRunner(user, repo string, runnerID int64) (*Response, error) {
+	if err := escapeValidatePathSegments(&user, &repo); err != nil {
+		return nil, err
 	}
+	return c.deleteActionRunner(fmt.Sprintf("/repos/%s/%s/actions/runners", user, repo), runnerID)
 }
@@ -0,0 +1,150 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+)
+
+// ActionArtifact represents an artifact uploaded by a workflow run
+type ActionArtifact struct {
+	ID                 int64                      `json:"id"`
+	Name               string                     `json:"name"`
+	SizeInBytes        int64                      `json:"size_in_bytes"`
+	URL                string                     `json:"url"`
+	ArchiveDownloadURL string                     `json:"archive_download_url"`
+	Expired            bool                       `json:"expired"`
+	WorkflowRun        *ActionWorkflowArtifactRun `json:"workflow_run"`
+	CreatedAt          time.Time                  `json:"created_at"`
+	UpdatedAt          time.Time                  `json:"updated_at"`
+	ExpiresAt          time.Time                  `json:"expires_at"`
+}
+
+// ActionWorkflowArtifactRun is the workflow run an artifact was uploaded by
+type ActionWorkflowArtifactRun struct {
+	ID           int64  `json:"id"`
+	RepositoryID int64  `json:"repository_id"`
+	HeadSha      string `json:"head_sha"`
+}
+
+// ActionArtifactsResponse is a page of artifacts
+type ActionArtifactsResponse struct {
+	Artifacts  []*ActionArtifact `json:"artifacts"`
+	TotalCount int64             `json:"total_count"`
+}
+
+// ListRepoActionArtifactsOptions options for listing artifacts
+type ListRepoActionArtifactsOptions struct {
+	ListOptions
+	// Name only lists the artifacts with this name
+	Name string
+}
+
+// QueryEncode turns options into querystring argument
+func (opt *ListRepoActionArtifactsOptions) QueryEncode() string {
+	query := opt.getURLQuery()
+	if opt.Name != "" {
+		query.Add("name", opt.Name)
+	}
+	return query.Encode()
+}
+
+// ListRepoActionArtifacts lists the artifacts of all workflow runs of a repository
+func (c *Client) ListRepoActionArtifacts(owner, repo string, opt ListRepoActionArtifactsOptions) (*ActionArtifactsResponse, *Response, error) {
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, nil, err
+	}
+	return c.listActionArtifacts(fmt.Sprintf("/repos/%s/%s/actions/artifacts", owner, repo), opt)
+}
+
+// ListRepoActionRunArtifacts lists the artifacts of a workflow run
+func (c *Client) ListRepoActionRunArtifacts(owner, repo string, runID int64, opt ListRepoActionArtifactsOptions) (*ActionArtifactsResponse, *Response, error) {
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, nil, err
+	}
+	return c.listActionArtifacts(fmt.Sprintf("/repos/%s/%s/actions/runs/%d/artifacts", owner, repo, runID), opt)
+}
+
+func (c *Client) listActionArtifacts(path string, opt ListRepoActionArtifactsOptions) (*ActionArtifactsResponse, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	opt.setDefaults()
+	artifacts := new(ActionArtifactsResponse)
+	link, _ := url.Parse(path)
+	link.RawQuery = opt.QueryEncode()
+	resp, err := c.getParsedResponse("GET", link.String(), jsonHeader, nil, artifacts)
+	return artifacts, resp, err
+}
+
+// GetRepoActionArtifact gets the metadata of an artifact
+func (c *Client) GetRepoActionArtifact(owner, repo string, artifactID int64) (*ActionArtifact, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, nil, err
+	}
+	artifact := new(ActionArtifact)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("/repos/%s/%s/actions/artifacts/%d", owner, repo, artifactID), jsonHeader, nil, artifact)
+	return artifact, resp, err
+}
+
+// GetRepoActionArtifactReader streams the zip archive of an artifact without buffering it,
+// the caller has to close the reader. Gitea answers with a redirect to a signed download url,
+// which is followed. Reading fails with an error wrapping io.ErrUnexpectedEOF if the archive
+// is shorter or longer than the size of the artifact, so a truncated download is not mistaken for a complete one.
+func (c *Client) GetRepoActionArtifactReader(owner, repo string, artifactID int64) (io.ReadCloser, *Response, error) {
+	artifact, resp, err := c.GetRepoActionArtifact(owner, repo, artifactID)
+	if err != nil {
+		return nil, resp, err
+	}
+	if artifact.Expired {
+		return nil, resp, fmt.Errorf("artifact %d has expired", artifactID)
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, nil, err
+	}
+	body, resp, err := c.getResponseReader("GET", fmt.Sprintf("/repos/%s/%s/actions/artifacts/%d/zip", owner, repo, artifactID), nil, nil)
+	if err != nil {
+		return body, resp, err
+	}
+	return &sizeVerifyingReader{ReadCloser: body, name: fmt.Sprintf("artifact %d", artifactID), size: artifact.SizeInBytes}, resp, nil
+}
+
+// DeleteRepoActionArtifact deletes an artifact
+func (c *Client) DeleteRepoActionArtifact(owner, repo string, artifactID int64) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/repos/%s/%s/actions/artifacts/%d", owner, repo, artifactID), jsonHeader, nil)
+	return resp, err
+}
+
+// sizeVerifyingReader fails reading a stream whose length differs from size
+type sizeVerifyingReader struct {
+	io.ReadCloser
+	name string
+	size int64
+	read int64
+}
+
+func (r *sizeVerifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.read += int64(n)
+	if r.read > r.size {
+		return n, fmt.Errorf("%s: read more than the expected %d bytes: %w", r.name, r.size, io.ErrUnexpectedEOF)
+	}
+	if err == io.EOF && r.read < r.size {
+		return n, fmt.Errorf("%s: read %d of %d bytes: %w", r.name, r.read, r.size, io.ErrUnexpectedEOF)
+	}
+	return n, err
+}
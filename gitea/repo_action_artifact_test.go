@@ -0,0 +1,89 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoActionArtifacts(t *testing.T) {
+	const archive = "PK fake zip archive"
+	var query string
+	deleted := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/artifacts", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"artifacts": [{"id": 1, "name": "dist"}, {"id": 2, "name": "dist"}], "total_count": 2}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/runs/7/artifacts", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"artifacts": [{"id": 1, "name": "dist", "workflow_run": {"id": 7}}], "total_count": 1}`))
+	})
+	// artifact 1 is complete, 2 is truncated, 3 is longer than announced and 4 has expired
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/artifacts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		size := len(archive)
+		if r.PathValue("id") == "3" {
+			size -= 3
+		}
+		expired := r.PathValue("id") == "4"
+		_, _ = w.Write([]byte(`{"id": ` + r.PathValue("id") + `, "name": "dist", "size_in_bytes": ` + strconv.Itoa(size) + `, "expired": ` + strconv.FormatBool(expired) + `}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/artifacts/{id}/zip", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/signed/"+r.PathValue("id")+"?sig=abc", http.StatusFound)
+	})
+	mux.HandleFunc("GET /signed/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "2" {
+			_, _ = w.Write([]byte(archive[:5]))
+			return
+		}
+		_, _ = w.Write([]byte(archive))
+	})
+	mux.HandleFunc("DELETE /api/v1/repos/acme/api/actions/artifacts/1", func(w http.ResponseWriter, r *http.Request) {
+		deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c, err := NewClient(server.URL, SetGiteaVersion("1.24.0"))
+	assert.NoError(t, err)
+
+	artifacts, _, err := c.ListRepoActionArtifacts("acme", "api", ListRepoActionArtifactsOptions{Name: "dist"})
+	assert.NoError(t, err)
+	assert.Len(t, artifacts.Artifacts, 2)
+	assert.Contains(t, query, "name=dist")
+	artifacts, _, err = c.ListRepoActionRunArtifacts("acme", "api", 7, ListRepoActionArtifactsOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, artifacts.Artifacts, 1) {
+		assert.EqualValues(t, 7, artifacts.Artifacts[0].WorkflowRun.ID)
+	}
+
+	reader, resp, err := c.GetRepoActionArtifactReader("acme", "api", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "/signed/1", resp.Request.URL.Path, "the redirect is followed")
+	data, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.NoError(t, reader.Close())
+	assert.Equal(t, archive, string(data))
+
+	for _, id := range []int64{2, 3} {
+		reader, _, err = c.GetRepoActionArtifactReader("acme", "api", id)
+		assert.NoError(t, err)
+		_, err = io.ReadAll(reader)
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF, id)
+		assert.NoError(t, reader.Close())
+	}
+
+	_, _, err = c.GetRepoActionArtifactReader("acme", "api", 4)
+	assert.EqualError(t, err, "artifact 4 has expired")
+
+	_, err = c.DeleteRepoActionArtifact("acme", "api", 1)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+}
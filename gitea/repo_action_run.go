@@ -0,0 +1,241 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+)
+
+// ActionWorkflowRun represents a run of a workflow of Gitea Actions
+type ActionWorkflowRun struct {
+	ID             int64       `json:"id"`
+	URL            string      `json:"url"`
+	HTMLURL        string      `json:"html_url"`
+	DisplayTitle   string      `json:"display_title"`
+	Path           string      `json:"path"`
+	Event          string      `json:"event"`
+	RunAttempt     int64       `json:"run_attempt"`
+	RunNumber      int64       `json:"run_number"`
+	RepositoryID   int64       `json:"repository_id,omitempty"`
+	HeadSha        string      `json:"head_sha"`
+	HeadBranch     string      `json:"head_branch,omitempty"`
+	Status         string      `json:"status"`
+	Conclusion     string      `json:"conclusion,omitempty"`
+	Actor          *User       `json:"actor,omitempty"`
+	TriggerActor   *User       `json:"trigger_actor,omitempty"`
+	Repository     *Repository `json:"repository,omitempty"`
+	HeadRepository *Repository `json:"head_repository,omitempty"`
+	StartedAt      time.Time   `json:"started_at"`
+	CompletedAt    time.Time   `json:"completed_at"`
+}
+
+// ActionWorkflowJob represents a job of a workflow run
+type ActionWorkflowJob struct {
+	ID          int64                 `json:"id"`
+	URL         string                `json:"url"`
+	HTMLURL     string                `json:"html_url"`
+	RunID       int64                 `json:"run_id"`
+	RunURL      string                `json:"run_url"`
+	Name        string                `json:"name"`
+	Labels      []string              `json:"labels"`
+	RunAttempt  int64                 `json:"run_attempt"`
+	HeadSha     string                `json:"head_sha"`
+	HeadBranch  string                `json:"head_branch,omitempty"`
+	Status      string                `json:"status"`
+	Conclusion  string                `json:"conclusion,omitempty"`
+	RunnerID    int64                 `json:"runner_id,omitempty"`
+	RunnerName  string                `json:"runner_name,omitempty"`
+	Steps       []*ActionWorkflowStep `json:"steps"`
+	CreatedAt   time.Time             `json:"created_at"`
+	StartedAt   time.Time             `json:"started_at"`
+	CompletedAt time.Time             `json:"completed_at"`
+}
+
+// ActionWorkflowStep represents a step of a workflow job
+type ActionWorkflowStep struct {
+	Name        string    `json:"name"`
+	Number      int64     `json:"number"`
+	Status      string    `json:"status"`
+	Conclusion  string    `json:"conclusion,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// ActionRunStatus is the status or conclusion of a workflow run or job
+type ActionRunStatus string
+
+const (
+	// ActionRunStatusQueued the run waits for a runner
+	ActionRunStatusQueued ActionRunStatus = "queued"
+	// ActionRunStatusInProgress the run is running
+	ActionRunStatusInProgress ActionRunStatus = "in_progress"
+	// ActionRunStatusCompleted the run has finished, see its conclusion
+	ActionRunStatusCompleted ActionRunStatus = "completed"
+	// ActionRunStatusSuccess the run has succeeded
+	ActionRunStatusSuccess ActionRunStatus = "success"
+	// ActionRunStatusFailure the run has failed
+	ActionRunStatusFailure ActionRunStatus = "failure"
+	// ActionRunStatusCancelled the run was cancelled
+	ActionRunStatusCancelled ActionRunStatus = "cancelled"
+	// ActionRunStatusSkipped the run was skipped
+	ActionRunStatusSkipped ActionRunStatus = "skipped"
+)
+
+// ActionWorkflowRunsResponse is a page of workflow runs
+type ActionWorkflowRunsResponse struct {
+	WorkflowRuns []*ActionWorkflowRun `json:"workflow_runs"`
+	TotalCount   int64                `json:"total_count"`
+}
+
+// ActionWorkflowJobsResponse is a page of workflow jobs
+type ActionWorkflowJobsResponse struct {
+	Jobs       []*ActionWorkflowJob `json:"jobs"`
+	TotalCount int64                `json:"total_count"`
+}
+
+// ListRepoActionRunsOptions options for listing the workflow runs of a repository
+type ListRepoActionRunsOptions struct {
+	ListOptions
+	Branch string
+	// Event is the event triggering the runs, e.g. push or pull_request
+	Event  string
+	Status ActionRunStatus
+	// Actor is the name of the user triggering the runs
+	Actor   string
+	HeadSHA string
+}
+
+// QueryEncode turns options into querystring argument
+func (opt *ListRepoActionRunsOptions) QueryEncode() string {
+	query := opt.getURLQuery()
+	if opt.Branch != "" {
+		query.Add("branch", opt.Branch)
+	}
+	if opt.Event != "" {
+		query.Add("event", opt.Event)
+	}
+	if opt.Status != "" {
+		query.Add("status", string(opt.Status))
+	}
+	if opt.Actor != "" {
+		query.Add("actor", opt.Actor)
+	}
+	if opt.HeadSHA != "" {
+		query.Add("head_sha", opt.HeadSHA)
+	}
+	return query.Encode()
+}
+
+// ListRepoActionRuns lists the workflow runs of a repository, newest first
+func (c *Client) ListRepoActionRuns(owner, repo string, opt ListRepoActionRunsOptions) (*ActionWorkflowRunsResponse, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, nil, err
+	}
+	opt.setDefaults()
+	runs := new(ActionWorkflowRunsResponse)
+	link, _ := url.Parse(fmt.Sprintf("/repos/%s/%s/actions/runs", owner, repo))
+	link.RawQuery = opt.QueryEncode()
+	resp, err := c.getParsedResponse("GET", link.String(), jsonHeader, nil, runs)
+	return runs, resp, err
+}
+
+// GetRepoActionRun gets a workflow run of a repository
+func (c *Client) GetRepoActionRun(owner, repo string, runID int64) (*ActionWorkflowRun, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, nil, err
+	}
+	run := new(ActionWorkflowRun)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("/repos/%s/%s/actions/runs/%d", owner, repo, runID), jsonHeader, nil, run)
+	return run, resp, err
+}
+
+// ListRepoActionJobsOptions options for listing the jobs of a workflow run
+type ListRepoActionJobsOptions struct {
+	ListOptions
+	Status ActionRunStatus
+}
+
+// QueryEncode turns options into querystring argument
+func (opt *ListRepoActionJobsOptions) QueryEncode() string {
+	query := opt.getURLQuery()
+	if opt.Status != "" {
+		query.Add("status", string(opt.Status))
+	}
+	return query.Encode()
+}
+
+// ListRepoActionRunJobs lists the jobs of a workflow run including their steps
+func (c *Client) ListRepoActionRunJobs(owner, repo string, runID int64, opt ListRepoActionJobsOptions) (*ActionWorkflowJobsResponse, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, nil, err
+	}
+	opt.setDefaults()
+	jobs := new(ActionWorkflowJobsResponse)
+	link, _ := url.Parse(fmt.Sprintf("/repos/%s/%s/actions/runs/%d/jobs", owner, repo, runID))
+	link.RawQuery = opt.QueryEncode()
+	resp, err := c.getParsedResponse("GET", link.String(), jsonHeader, nil, jobs)
+	return jobs, resp, err
+}
+
+// GetRepoActionJob gets a job of a workflow run including its steps
+func (c *Client) GetRepoActionJob(owner, repo string, jobID int64) (*ActionWorkflowJob, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, nil, err
+	}
+	job := new(ActionWorkflowJob)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("/repos/%s/%s/actions/jobs/%d", owner, repo, jobID), jsonHeader, nil, job)
+	return job, resp, err
+}
+
+// GetRepoActionJobLogsReader streams the logs of a job as plain text,
+// the caller has to close the reader
+func (c *Client) GetRepoActionJobLogsReader(owner, repo string, jobID int64) (io.ReadCloser, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, nil, err
+	}
+	return c.getResponseReader("GET", fmt.Sprintf("/repos/%s/%s/actions/jobs/%d/logs", owner, repo, jobID), nil, nil)
+}
+
+// CancelRepoActionRun cancels a queued or running workflow run, it requires Gitea 1.25 or newer
+func (c *Client) CancelRepoActionRun(owner, repo string, runID int64) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_25_0); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("POST", fmt.Sprintf("/repos/%s/%s/actions/runs/%d/cancel", owner, repo, runID), jsonHeader, nil)
+	return resp, err
+}
+
+// RerunRepoActionRun reruns all jobs of a completed workflow run, it requires Gitea 1.25 or newer
+func (c *Client) RerunRepoActionRun(owner, repo string, runID int64) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_25_0); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("POST", fmt.Sprintf("/repos/%s/%s/actions/runs/%d/rerun", owner, repo, runID), jsonHeader, nil)
+	return resp, err
+}
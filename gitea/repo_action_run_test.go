@@ -0,0 +1,94 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoActionRuns(t *testing.T) {
+	var query string
+	var posted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/runs", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"workflow_runs": [{"id": 7, "status": "completed", "conclusion": "success", "head_branch": "main"}], "total_count": 12}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/runs/7", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": 7, "run_number": 3, "status": "completed", "actor": {"login": "alice"}}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/runs/7/jobs", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jobs": [{"id": 9, "run_id": 7, "name": "test", "steps": [{"name": "checkout", "number": 1, "status": "completed"}]}], "total_count": 1}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/jobs/9/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("line 1\nline 2\n"))
+	})
+	mux.HandleFunc("POST /api/v1/repos/acme/api/actions/runs/{id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		posted = append(posted, r.PathValue("id")+"/"+r.PathValue("action"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c, err := NewClient(server.URL, SetGiteaVersion("1.24.0"))
+	assert.NoError(t, err)
+
+	runs, _, err := c.ListRepoActionRuns("acme", "api", ListRepoActionRunsOptions{
+		ListOptions: ListOptions{Page: 2, PageSize: 5},
+		Branch:      "main",
+		Event:       "push",
+		Status:      ActionRunStatusSuccess,
+		Actor:       "alice",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "actor=alice&branch=main&event=push&limit=5&page=2&status=success", query)
+	assert.EqualValues(t, 12, runs.TotalCount)
+	if assert.Len(t, runs.WorkflowRuns, 1) {
+		assert.Equal(t, "success", runs.WorkflowRuns[0].Conclusion)
+	}
+
+	run, _, err := c.GetRepoActionRun("acme", "api", 7)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, run.RunNumber)
+	assert.Equal(t, "alice", run.Actor.UserName)
+
+	jobs, _, err := c.ListRepoActionRunJobs("acme", "api", 7, ListRepoActionJobsOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, jobs.Jobs, 1) && assert.Len(t, jobs.Jobs[0].Steps, 1) {
+		assert.Equal(t, "checkout", jobs.Jobs[0].Steps[0].Name)
+	}
+
+	logs, _, err := c.GetRepoActionJobLogsReader("acme", "api", 9)
+	assert.NoError(t, err)
+	data, err := io.ReadAll(logs)
+	assert.NoError(t, err)
+	assert.NoError(t, logs.Close())
+	assert.Equal(t, "line 1\nline 2\n", string(data))
+
+	_, _, err = c.GetRepoActionJobLogsReader("acme", "api", 10)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// cancel and rerun need a newer server
+	_, err = c.CancelRepoActionRun("acme", "api", 7)
+	assert.Error(t, err)
+	assert.Empty(t, posted)
+	c, err = NewClient(server.URL, SetGiteaVersion("1.25.0"))
+	assert.NoError(t, err)
+	_, err = c.CancelRepoActionRun("acme", "api", 7)
+	assert.NoError(t, err)
+	_, err = c.RerunRepoActionRun("acme", "api", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"7/cancel", "7/rerun"}, posted)
+
+	c, err = NewClient(server.URL, SetGiteaVersion("1.23.0"))
+	assert.NoError(t, err)
+	_, _, err = c.ListRepoActionRuns("acme", "api", ListRepoActionRunsOptions{})
+	assert.Error(t, err)
+}
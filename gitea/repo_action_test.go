@@ -38,4 +38,11 @@ func TestCreateRepoActionSecret(t *testing.T) {
 	secrets, _, err := c.ListRepoActionSecret(newRepo.Owner.UserName, newRepo.Name, ListRepoActionSecretOption{})
 	assert.NoError(t, err)
 	assert.Len(t, secrets, 1)
+
+	// delete secret
+	resp, err = c.DeleteRepoActionSecret(newRepo.Owner.UserName, newRepo.Name, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	_, err = c.DeleteRepoActionSecret(newRepo.Owner.UserName, newRepo.Name, "test")
+	assert.ErrorIs(t, err, ErrNotFound)
 }
@@ -0,0 +1,258 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ActionWorkflowState is the state of a workflow
+type ActionWorkflowState string
+
+const (
+	// ActionWorkflowStateActive the workflow is run on its triggers
+	ActionWorkflowStateActive ActionWorkflowState = "active"
+	// ActionWorkflowStateDisabled the workflow was disabled and is not run
+	ActionWorkflowStateDisabled ActionWorkflowState = "disabled_manually"
+)
+
+// ActionWorkflow represents a workflow file of Gitea Actions
+type ActionWorkflow struct {
+	ID        string              `json:"id"`
+	Name      string              `json:"name"`
+	Path      string              `json:"path"`
+	State     ActionWorkflowState `json:"state"`
+	URL       string              `json:"url"`
+	HTMLURL   string              `json:"html_url"`
+	BadgeURL  string              `json:"badge_url"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// ActionWorkflowsResponse is the list of workflows of a repository
+type ActionWorkflowsResponse struct {
+	Workflows  []*ActionWorkflow `json:"workflows"`
+	TotalCount int64             `json:"total_count"`
+}
+
+// ListRepoActionWorkflows lists the workflows of a repository with their state
+func (c *Client) ListRepoActionWorkflows(owner, repo string) (*ActionWorkflowsResponse, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
+		return nil, nil, err
+	}
+	workflows := new(ActionWorkflowsResponse)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("/repos/%s/%s/actions/workflows", owner, repo), jsonHeader, nil, workflows)
+	return workflows, resp, err
+}
+
+// GetRepoActionWorkflow gets a workflow by its file name, e.g. "build.yml"
+func (c *Client) GetRepoActionWorkflow(owner, repo, workflowFile string) (*ActionWorkflow, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo, &workflowFile); err != nil {
+		return nil, nil, err
+	}
+	workflow := new(ActionWorkflow)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("/repos/%s/%s/actions/workflows/%s", owner, repo, workflowFile), jsonHeader, nil, workflow)
+	return workflow, resp, err
+}
+
+// EnableRepoActionWorkflow enables a disabled workflow
+func (c *Client) EnableRepoActionWorkflow(owner, repo, workflowFile string) (*Response, error) {
+	return c.setRepoActionWorkflowState(owner, repo, workflowFile, "enable")
+}
+
+// DisableRepoActionWorkflow disables a workflow, it is not run until it is enabled again
+func (c *Client) DisableRepoActionWorkflow(owner, repo, workflowFile string) (*Response, error) {
+	return c.setRepoActionWorkflowState(owner, repo, workflowFile, "disable")
+}
+
+func (c *Client) setRepoActionWorkflowState(owner, repo, workflowFile, action string) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo, &workflowFile); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("PUT", fmt.Sprintf("/repos/%s/%s/actions/workflows/%s/%s", owner, repo, workflowFile, action), jsonHeader, nil)
+	return resp, err
+}
+
+// WorkflowDispatchInputType is the type of a workflow_dispatch input
+type WorkflowDispatchInputType string
+
+const (
+	// WorkflowDispatchInputString any text, the default type
+	WorkflowDispatchInputString WorkflowDispatchInputType = "string"
+	// WorkflowDispatchInputBoolean "true" or "false"
+	WorkflowDispatchInputBoolean WorkflowDispatchInputType = "boolean"
+	// WorkflowDispatchInputNumber a number
+	WorkflowDispatchInputNumber WorkflowDispatchInputType = "number"
+	// WorkflowDispatchInputChoice one of the options of the input
+	WorkflowDispatchInputChoice WorkflowDispatchInputType = "choice"
+	// WorkflowDispatchInputEnvironment the name of an environment
+	WorkflowDispatchInputEnvironment WorkflowDispatchInputType = "environment"
+)
+
+// WorkflowDispatchInput is an input declared by on.workflow_dispatch.inputs of a workflow
+type WorkflowDispatchInput struct {
+	Description string                    `yaml:"description"`
+	Required    bool                      `yaml:"required"`
+	Default     string                    `yaml:"default"`
+	Type        WorkflowDispatchInputType `yaml:"type"`
+	Options     []string                  `yaml:"options"`
+}
+
+// ParseWorkflowDispatchInputs returns the inputs declared by a workflow file. It fails
+// if the workflow can not be triggered by workflow_dispatch.
+func ParseWorkflowDispatchInputs(workflow []byte) (map[string]*WorkflowDispatchInput, error) {
+	var doc struct {
+		On yaml.Node `yaml:"on"`
+	}
+	if err := yaml.Unmarshal(workflow, &doc); err != nil {
+		return nil, fmt.Errorf("parsing workflow: %w", err)
+	}
+	inputs := make(map[string]*WorkflowDispatchInput)
+	switch doc.On.Kind {
+	case yaml.ScalarNode:
+		// on: workflow_dispatch
+		if doc.On.Value == "workflow_dispatch" {
+			return inputs, nil
+		}
+	case yaml.SequenceNode:
+		// on: [push, workflow_dispatch]
+		for _, event := range doc.On.Content {
+			if event.Value == "workflow_dispatch" {
+				return inputs, nil
+			}
+		}
+	case yaml.MappingNode:
+		// on: {workflow_dispatch: {inputs: ...}}
+		var events map[string]*struct {
+			Inputs map[string]*WorkflowDispatchInput `yaml:"inputs"`
+		}
+		if err := doc.On.Decode(&events); err != nil {
+			return nil, fmt.Errorf("parsing workflow triggers: %w", err)
+		}
+		if dispatch, ok := events["workflow_dispatch"]; ok {
+			if dispatch != nil {
+				for name, input := range dispatch.Inputs {
+					if input == nil {
+						input = &WorkflowDispatchInput{}
+					}
+					if input.Type == "" {
+						input.Type = WorkflowDispatchInputString
+					}
+					inputs[name] = input
+				}
+			}
+			return inputs, nil
+		}
+	}
+	return nil, fmt.Errorf("workflow has no workflow_dispatch trigger")
+}
+
+// ValidateWorkflowDispatchInputs checks inputs against the inputs declared by a workflow:
+// unknown inputs, missing required inputs without default and values not matching the type
+// of their input are reported. The error wraps ErrValidation.
+func ValidateWorkflowDispatchInputs(declared map[string]*WorkflowDispatchInput, inputs map[string]string) error {
+	var problems []string
+	for name := range inputs {
+		if _, ok := declared[name]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown input %q", name))
+		}
+	}
+	for name, input := range declared {
+		value, ok := inputs[name]
+		if !ok {
+			if input.Required && input.Default == "" {
+				problems = append(problems, fmt.Sprintf("input %q is required", name))
+			}
+			continue
+		}
+		switch input.Type {
+		case WorkflowDispatchInputBoolean:
+			if value != "true" && value != "false" {
+				problems = append(problems, fmt.Sprintf("input %q must be true or false", name))
+			}
+		case WorkflowDispatchInputNumber:
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				problems = append(problems, fmt.Sprintf("input %q must be a number", name))
+			}
+		case WorkflowDispatchInputChoice:
+			if !slices.Contains(input.Options, value) {
+				problems = append(problems, fmt.Sprintf("input %q must be one of %s", name, strings.Join(input.Options, ", ")))
+			}
+		}
+	}
+	if len(problems) != 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrValidation, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// GetWorkflowDispatchInputs returns the inputs a workflow declares at a ref,
+// read from the workflow file with GetFile
+func (c *Client) GetWorkflowDispatchInputs(owner, repo, workflowFile, ref string) (map[string]*WorkflowDispatchInput, *Response, error) {
+	workflow, resp, err := c.GetRepoActionWorkflow(owner, repo, workflowFile)
+	if err != nil {
+		return nil, resp, err
+	}
+	data, resp, err := c.GetFile(owner, repo, ref, workflow.Path)
+	if err != nil {
+		return nil, resp, err
+	}
+	inputs, err := ParseWorkflowDispatchInputs(data)
+	if err != nil {
+		return nil, resp, fmt.Errorf("%s: %w", workflow.Path, err)
+	}
+	return inputs, resp, nil
+}
+
+// dispatchWorkflowOption is the body of a workflow dispatch
+type dispatchWorkflowOption struct {
+	// Ref is the branch or tag to run the workflow on
+	Ref    string            `json:"ref"`
+	Inputs map[string]string `json:"inputs,omitempty"`
+}
+
+// DispatchWorkflow triggers a workflow_dispatch run of a workflow, e.g. "deploy.yml", on a branch or tag.
+// The inputs are validated against the inputs the workflow declares at ref before it is triggered,
+// so mistakes are reported instead of being ignored or defaulted by the server.
+func (c *Client) DispatchWorkflow(owner, repo, workflowFile, ref string, inputs map[string]string) (*Response, error) {
+	if ref == "" {
+		return nil, fmt.Errorf("ref required")
+	}
+	declared, resp, err := c.GetWorkflowDispatchInputs(owner, repo, workflowFile, ref)
+	if err != nil {
+		return resp, err
+	}
+	if err := ValidateWorkflowDispatchInputs(declared, inputs); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&owner, &repo, &workflowFile); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&dispatchWorkflowOption{Ref: ref, Inputs: inputs})
+	if err != nil {
+		return nil, err
+	}
+	_, resp, err = c.getResponse("POST", fmt.Sprintf("/repos/%s/%s/actions/workflows/%s/dispatches", owner, repo, workflowFile), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
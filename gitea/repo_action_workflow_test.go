@@ -0,0 +1,141 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const deployWorkflow = `name: deploy
+on:
+  push:
+    branches: [main]
+  workflow_dispatch:
+    inputs:
+      environment:
+        description: Where to deploy
+        type: choice
+        options: [staging, production]
+        required: true
+      dry_run:
+        type: boolean
+        default: "false"
+      replicas:
+        type: number
+      version:
+        required: true
+        default: latest
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo deploying
+`
+
+func TestParseWorkflowDispatchInputs(t *testing.T) {
+	inputs, err := ParseWorkflowDispatchInputs([]byte(deployWorkflow))
+	assert.NoError(t, err)
+	assert.Len(t, inputs, 4)
+	assert.Equal(t, &WorkflowDispatchInput{
+		Description: "Where to deploy",
+		Required:    true,
+		Type:        WorkflowDispatchInputChoice,
+		Options:     []string{"staging", "production"},
+	}, inputs["environment"])
+	assert.Equal(t, WorkflowDispatchInputString, inputs["version"].Type)
+
+	for _, workflow := range []string{"on: workflow_dispatch", "on: [push, workflow_dispatch]", "on:\n  workflow_dispatch:\n"} {
+		inputs, err = ParseWorkflowDispatchInputs([]byte(workflow))
+		assert.NoError(t, err, workflow)
+		assert.Empty(t, inputs, workflow)
+	}
+	_, err = ParseWorkflowDispatchInputs([]byte("on: [push]"))
+	assert.EqualError(t, err, "workflow has no workflow_dispatch trigger")
+	_, err = ParseWorkflowDispatchInputs([]byte("on: [push"))
+	assert.Error(t, err)
+}
+
+func TestValidateWorkflowDispatchInputs(t *testing.T) {
+	declared, err := ParseWorkflowDispatchInputs([]byte(deployWorkflow))
+	assert.NoError(t, err)
+
+	assert.NoError(t, ValidateWorkflowDispatchInputs(declared, map[string]string{"environment": "staging"}))
+	assert.NoError(t, ValidateWorkflowDispatchInputs(declared, map[string]string{"environment": "production", "dry_run": "true", "replicas": "3", "version": "v1.2"}))
+
+	err = ValidateWorkflowDispatchInputs(declared, map[string]string{"environment": "qa", "dry_run": "yes", "replicas": "many", "force": "true"})
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.EqualError(t, err, `validation failed: input "dry_run" must be true or false; input "environment" must be one of staging, production; input "replicas" must be a number; unknown input "force"`)
+	assert.EqualError(t, ValidateWorkflowDispatchInputs(declared, nil), `validation failed: input "environment" is required`)
+}
+
+func TestDispatchWorkflow(t *testing.T) {
+	var dispatched []map[string]any
+	var states []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/workflows", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"workflows": [{"id": "deploy.yml", "name": "deploy", "path": ".gitea/workflows/deploy.yml", "state": "disabled_manually"}], "total_count": 1}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/acme/api/actions/workflows/deploy.yml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "deploy.yml", "name": "deploy", "path": ".gitea/workflows/deploy.yml", "state": "active"}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/acme/api/raw/.gitea/workflows/deploy.yml", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != "main" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(deployWorkflow))
+	})
+	mux.HandleFunc("PUT /api/v1/repos/acme/api/actions/workflows/deploy.yml/{action}", func(w http.ResponseWriter, r *http.Request) {
+		states = append(states, r.PathValue("action"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("POST /api/v1/repos/acme/api/actions/workflows/deploy.yml/dispatches", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		dispatched = append(dispatched, body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c, err := NewClient(server.URL, SetGiteaVersion("1.24.0"))
+	assert.NoError(t, err)
+
+	workflows, _, err := c.ListRepoActionWorkflows("acme", "api")
+	assert.NoError(t, err)
+	if assert.Len(t, workflows.Workflows, 1) {
+		assert.Equal(t, ActionWorkflowStateDisabled, workflows.Workflows[0].State)
+	}
+	_, err = c.EnableRepoActionWorkflow("acme", "api", "deploy.yml")
+	assert.NoError(t, err)
+	_, err = c.DisableRepoActionWorkflow("acme", "api", "deploy.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"enable", "disable"}, states)
+
+	inputs, _, err := c.GetWorkflowDispatchInputs("acme", "api", "deploy.yml", "main")
+	assert.NoError(t, err)
+	assert.Len(t, inputs, 4)
+
+	resp, err := c.DispatchWorkflow("acme", "api", "deploy.yml", "main", map[string]string{"environment": "staging", "replicas": "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, []map[string]any{{
+		"ref":    "main",
+		"inputs": map[string]any{"environment": "staging", "replicas": "2"},
+	}}, dispatched)
+
+	// invalid inputs are not dispatched
+	_, err = c.DispatchWorkflow("acme", "api", "deploy.yml", "main", map[string]string{"environment": "moon"})
+	assert.ErrorIs(t, err, ErrValidation)
+	_, err = c.DispatchWorkflow("acme", "api", "deploy.yml", "", nil)
+	assert.EqualError(t, err, "ref required")
+	_, err = c.DispatchWorkflow("acme", "api", "deploy.yml", "feature", nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Len(t, dispatched, 1)
+}
@@ -93,11 +93,11 @@ func (c *Client) DeleteRepoBranch(user, repo, branch string) (bool, *Response, e
 	if err := c.checkServerVersionGreaterThanOrEqual(version1_12_0); err != nil {
 		return false, nil, err
 	}
-	status, resp, err := c.getStatusCode("DELETE", fmt.Sprintf("/repos/%s/%s/branches/%s", user, repo, branch), nil, nil)
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/repos/%s/%s/branches/%s", user, repo, branch), nil, nil)
 	if err != nil {
 		return false, resp, err
 	}
-	return status == 204, resp, nil
+	return true, resp, nil
 }
 
 // CreateBranchOption options when creating a branch in a repository
@@ -40,7 +40,7 @@ func TestRepoBranches(t *testing.T) {
 	assert.EqualValues(t, branches["update"].Commit.Added, b.Commit.Added)
 
 	s, _, err := c.DeleteRepoBranch(repo.Owner.UserName, repo.Name, "main")
-	assert.NoError(t, err)
+	assert.ErrorIs(t, err, ErrForbidden, "the default branch can not be deleted")
 	assert.False(t, s)
 	s, _, err = c.DeleteRepoBranch(repo.Owner.UserName, repo.Name, "feature")
 	assert.NoError(t, err)
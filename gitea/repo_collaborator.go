@@ -8,6 +8,7 @@ package gitea
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
@@ -41,14 +42,14 @@ func (c *Client) IsCollaborator(user, repo, collaborator string) (bool, *Respons
 	if err := escapeValidatePathSegments(&user, &repo, &collaborator); err != nil {
 		return false, nil, err
 	}
-	status, resp, err := c.getStatusCode("GET", fmt.Sprintf("/repos/%s/%s/collaborators/%s", user, repo, collaborator), nil, nil)
+	_, resp, err := c.getResponse("GET", fmt.Sprintf("/repos/%s/%s/collaborators/%s", user, repo, collaborator), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, resp, nil
+	}
 	if err != nil {
 		return false, resp, err
 	}
-	if status == 204 {
-		return true, resp, nil
-	}
-	return false, resp, nil
+	return true, resp, nil
 }
 
 // CollaboratorPermission gets collaborator permission of a repository
@@ -252,14 +252,8 @@ func (c *Client) DeleteFile(owner, repo, filepath string, opt DeleteFileOptions)
 	if err != nil {
 		return nil, err
 	}
-	status, resp, err := c.getStatusCode("DELETE", fmt.Sprintf("/repos/%s/%s/contents/%s", owner, repo, filepath), jsonHeader, bytes.NewReader(body))
-	if err != nil {
-		return resp, err
-	}
-	if status != 200 && status != 204 {
-		return resp, fmt.Errorf("unexpected Status: %d", status)
-	}
-	return resp, nil
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/repos/%s/%s/contents/%s", owner, repo, filepath), jsonHeader, bytes.NewReader(body))
+	return resp, err
 }
 
 func (c *Client) setDefaultBranchForOldVersions(owner, repo, branch string) (string, error) {
@@ -5,8 +5,8 @@
 package gitea
 
 import (
+	"errors"
 	"fmt"
-	"net/http"
 )
 
 // ListStargazersOptions options for listing a repository's stargazers
@@ -48,17 +48,13 @@ func (c *Client) IsRepoStarring(user, repo string) (bool, *Response, error) {
 		return false, nil, err
 	}
 	_, resp, err := c.getResponse("GET", fmt.Sprintf("/user/starred/%s/%s", user, repo), jsonHeader, nil)
-	if resp != nil {
-		switch resp.StatusCode {
-		case http.StatusNotFound:
-			return false, resp, nil
-		case http.StatusNoContent:
-			return true, resp, nil
-		default:
-			return false, resp, fmt.Errorf("unexpected status code '%d'", resp.StatusCode)
-		}
+	if errors.Is(err, ErrNotFound) {
+		return false, resp, nil
 	}
-	return false, nil, err
+	if err != nil {
+		return false, resp, err
+	}
+	return true, resp, nil
 }
 
 // StarRepo star specified repo as the authenticated user
@@ -67,15 +63,7 @@ func (c *Client) StarRepo(user, repo string) (*Response, error) {
 		return nil, err
 	}
 	_, resp, err := c.getResponse("PUT", fmt.Sprintf("/user/starred/%s/%s", user, repo), jsonHeader, nil)
-	if resp != nil {
-		switch resp.StatusCode {
-		case http.StatusNoContent:
-			return resp, nil
-		default:
-			return resp, fmt.Errorf("unexpected status code '%d'", resp.StatusCode)
-		}
-	}
-	return nil, err
+	return resp, err
 }
 
 // UnStarRepo remove star to specified repo as the authenticated user
@@ -84,13 +72,5 @@ func (c *Client) UnStarRepo(user, repo string) (*Response, error) {
 		return nil, err
 	}
 	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/user/starred/%s/%s", user, repo), jsonHeader, nil)
-	if resp != nil {
-		switch resp.StatusCode {
-		case http.StatusNoContent:
-			return resp, nil
-		default:
-			return resp, fmt.Errorf("unexpected status code '%d'", resp.StatusCode)
-		}
-	}
-	return nil, err
+	return resp, err
 }
@@ -5,8 +5,8 @@
 package gitea
 
 import (
+	"errors"
 	"fmt"
-	"net/http"
 	"time"
 )
 
@@ -42,18 +42,14 @@ func (c *Client) CheckRepoWatch(owner, repo string) (bool, *Response, error) {
 	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
 		return false, nil, err
 	}
-	status, resp, err := c.getStatusCode("GET", fmt.Sprintf("/repos/%s/%s/subscription", owner, repo), nil, nil)
+	_, resp, err := c.getResponse("GET", fmt.Sprintf("/repos/%s/%s/subscription", owner, repo), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, resp, nil
+	}
 	if err != nil {
 		return false, resp, err
 	}
-	switch status {
-	case http.StatusNotFound:
-		return false, resp, nil
-	case http.StatusOK:
-		return true, resp, nil
-	default:
-		return false, resp, fmt.Errorf("unexpected Status: %d", status)
-	}
+	return true, resp, nil
 }
 
 // WatchRepo start to watch a repository
@@ -61,14 +57,8 @@ func (c *Client) WatchRepo(owner, repo string) (*Response, error) {
 	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
 		return nil, err
 	}
-	status, resp, err := c.getStatusCode("PUT", fmt.Sprintf("/repos/%s/%s/subscription", owner, repo), nil, nil)
-	if err != nil {
-		return resp, err
-	}
-	if status == http.StatusOK {
-		return resp, nil
-	}
-	return resp, fmt.Errorf("unexpected Status: %d", status)
+	_, resp, err := c.getResponse("PUT", fmt.Sprintf("/repos/%s/%s/subscription", owner, repo), nil, nil)
+	return resp, err
 }
 
 // UnWatchRepo stop to watch a repository
@@ -76,12 +66,6 @@ func (c *Client) UnWatchRepo(owner, repo string) (*Response, error) {
 	if err := escapeValidatePathSegments(&owner, &repo); err != nil {
 		return nil, err
 	}
-	status, resp, err := c.getStatusCode("DELETE", fmt.Sprintf("/repos/%s/%s/subscription", owner, repo), nil, nil)
-	if err != nil {
-		return resp, err
-	}
-	if status == http.StatusNoContent {
-		return resp, nil
-	}
-	return resp, fmt.Errorf("unexpected Status: %d", status)
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/repos/%s/%s/subscription", owner, repo), nil, nil)
+	return resp, err
 }
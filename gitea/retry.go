@@ -0,0 +1,185 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how the client retries requests that failed because
+// of a transient error, like a connection reset or a 502, 503 or 429 response.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// A value lower than 2 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the base delay before the first retry,
+	// it is doubled for every following attempt and jittered.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts.
+	// If the server asks for a longer delay via Retry-After, the request is not retried.
+	MaxBackoff time.Duration
+	// RetryStatusCodes are the status codes considered transient,
+	// if empty 429, 502, 503 and 504 are used.
+	RetryStatusCodes []int
+	// RetryNonIdempotent allows to also retry POST and PATCH requests,
+	// which may result in the action being executed twice.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy with sensible defaults
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+var defaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// SetRetryPolicy is an option for NewClient to retry requests failing with transient errors
+func SetRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(client *Client) error {
+		client.SetRetryPolicy(policy)
+		return nil
+	}
+}
+
+// SetRetryPolicy sets the policy used to retry requests failing with transient errors.
+// Use a zero RetryPolicy to disable retries.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.mutex.Lock()
+	c.retryPolicy = policy
+	c.mutex.Unlock()
+}
+
+func (p RetryPolicy) enabled() bool {
+	return p.MaxAttempts > 1
+}
+
+// allowsMethod reports if requests of the given method may be sent more than once
+func (p RetryPolicy) allowsMethod(method string) bool {
+	if p.RetryNonIdempotent {
+		return true
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func (p RetryPolicy) retryStatus(code int) bool {
+	codes := p.RetryStatusCodes
+	if len(codes) == 0 {
+		codes = defaultRetryStatusCodes
+	}
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the jittered delay to wait before the given attempt (starting at 1)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || d < p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	// equal jitter: keep half of the delay and randomize the other half
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// nextRetry decides if a finished attempt should be retried and how long to wait before
+func (p RetryPolicy) nextRetry(attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if err != nil {
+		if !isTransientError(err) {
+			return 0, false
+		}
+		return p.backoff(attempt), true
+	}
+	if !p.retryStatus(resp.StatusCode) {
+		return 0, false
+	}
+	wait := p.backoff(attempt)
+	if after, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		if p.MaxBackoff > 0 && after > p.MaxBackoff {
+			return 0, false
+		}
+		wait = after
+	}
+	return wait, true
+}
+
+// isTransientError reports if a transport error is worth another attempt
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseRetryAfter parses a Retry-After header, which is either delay-seconds or a http-date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for the given duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
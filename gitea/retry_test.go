@@ -0,0 +1,69 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var calls int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	c, err := NewClient(server.URL, SetGiteaVersion(""), SetRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	}))
+	assert.NoError(t, err)
+
+	_, resp, err := c.getResponse("PUT", "/test", jsonHeader, strings.NewReader("payload"))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.EqualValues(t, 3, calls)
+	assert.Equal(t, []string{"payload", "payload", "payload"}, bodies)
+
+	// non-idempotent requests are not retried by default
+	atomic.StoreInt32(&calls, 0)
+	_, resp, err = c.getResponse("POST", "/test", jsonHeader, strings.NewReader("payload"))
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.EqualValues(t, 1, calls)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for attempt, limit := range []time.Duration{100, 200, 300, 300} {
+		d := p.backoff(attempt + 1)
+		assert.GreaterOrEqual(t, d, limit*time.Millisecond/2)
+		assert.LessOrEqual(t, d, limit*time.Millisecond)
+	}
+
+	wait, ok := p.nextRetry(1, &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"1"}}}, nil)
+	assert.False(t, ok, "Retry-After above MaxBackoff should not be retried")
+	assert.Zero(t, wait)
+
+	_, ok = p.nextRetry(1, &http.Response{StatusCode: http.StatusNotFound}, nil)
+	assert.False(t, ok)
+}
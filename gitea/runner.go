@@ -0,0 +1,91 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// RegistrationToken is a token to register an act_runner with
+type RegistrationToken struct {
+	Token string `json:"token"`
+}
+
+// ActionRunnerStatus is the status of a runner
+type ActionRunnerStatus string
+
+const (
+	// ActionRunnerStatusOffline the runner has not contacted Gitea recently
+	ActionRunnerStatusOffline ActionRunnerStatus = "offline"
+	// ActionRunnerStatusIdle the runner is online and waits for jobs
+	ActionRunnerStatusIdle ActionRunnerStatus = "idle"
+	// ActionRunnerStatusActive the runner is online and runs a job
+	ActionRunnerStatusActive ActionRunnerStatus = "active"
+)
+
+// ActionRunner represents a runner registered with Gitea Actions
+type ActionRunner struct {
+	ID        int64                `json:"id"`
+	Name      string               `json:"name"`
+	Status    ActionRunnerStatus   `json:"status"`
+	Busy      bool                 `json:"busy"`
+	Ephemeral bool                 `json:"ephemeral"`
+	Labels    []*ActionRunnerLabel `json:"labels"`
+}
+
+// ActionRunnerLabel is a label of a runner, jobs are run by runners with the labels of their runs-on
+type ActionRunnerLabel struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// Type is "read-only" for the labels reported by the runner and "custom" for others
+	Type string `json:"type"`
+}
+
+// ActionRunnersResponse is a page of runners
+type ActionRunnersResponse struct {
+	Runners    []*ActionRunner `json:"runners"`
+	TotalCount int64           `json:"total_count"`
+}
+
+// ListActionRunnersOptions options for listing runners
+type ListActionRunnersOptions struct {
+	ListOptions
+}
+
+func (c *Client) getRegistrationToken(path string) (*RegistrationToken, *Response, error) {
+	token := new(RegistrationToken)
+	resp, err := c.getParsedResponse("GET", path, jsonHeader, nil, token)
+	return token, resp, err
+}
+
+func (c *Client) listActionRunners(path string, opt ListActionRunnersOptions) (*ActionRunnersResponse, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	opt.setDefaults()
+	runners := new(ActionRunnersResponse)
+	link, _ := url.Parse(path)
+	link.RawQuery = opt.getURLQuery().Encode()
+	resp, err := c.getParsedResponse("GET", link.String(), jsonHeader, nil, runners)
+	return runners, resp, err
+}
+
+func (c *Client) getActionRunner(path string, runnerID int64) (*ActionRunner, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, nil, err
+	}
+	runner := new(ActionRunner)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("%s/%d", path, runnerID), jsonHeader, nil, runner)
+	return runner, resp, err
+}
+
+func (c *Client) deleteActionRunner(path string, runnerID int64) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_24_0); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("%s/%d", path, runnerID), jsonHeader, nil)
+	return resp, err
+}
@@ -0,0 +1,97 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionRunners(t *testing.T) {
+	var requests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/registration-token"):
+			_, _ = w.Write([]byte(`{"token": "t0ken"}`))
+		case strings.HasSuffix(r.URL.Path, "/runners"):
+			_, _ = w.Write([]byte(`{"runners": [{"id": 3, "name": "builder", "status": "offline", "labels": [{"id": 1, "name": "ubuntu-latest", "type": "read-only"}]}], "total_count": 1}`))
+		case strings.HasSuffix(r.URL.Path, "/runners/3"):
+			_, _ = w.Write([]byte(`{"id": 3, "name": "builder", "status": "active", "busy": true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("DELETE /api/v1/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, "DELETE "+r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c, err := NewClient(server.URL, SetGiteaVersion("1.24.0"))
+	assert.NoError(t, err)
+
+	token, _, err := c.GetRepoRunnerRegistrationToken("acme", "api")
+	assert.NoError(t, err)
+	assert.Equal(t, "t0ken", token.Token)
+	_, _, err = c.GetOrgRunnerRegistrationToken("acme")
+	assert.NoError(t, err)
+	_, _, err = c.AdminGetRunnerRegistrationToken()
+	assert.NoError(t, err)
+
+	runners, _, err := c.ListOrgActionRunners("acme", ListActionRunnersOptions{})
+	assert.NoError(t, err)
+	if assert.Len(t, runners.Runners, 1) {
+		assert.Equal(t, ActionRunnerStatusOffline, runners.Runners[0].Status)
+		assert.Equal(t, "ubuntu-latest", runners.Runners[0].Labels[0].Name)
+	}
+	_, _, err = c.ListRepoActionRunners("acme", "api", ListActionRunnersOptions{})
+	assert.NoError(t, err)
+	_, _, err = c.AdminListActionRunners(ListActionRunnersOptions{})
+	assert.NoError(t, err)
+
+	runner, _, err := c.GetRepoActionRunner("acme", "api", 3)
+	assert.NoError(t, err)
+	assert.True(t, runner.Busy)
+	_, _, err = c.GetOrgActionRunner("acme", 3)
+	assert.NoError(t, err)
+	_, _, err = c.AdminGetActionRunner(3)
+	assert.NoError(t, err)
+
+	_, err = c.DeleteRepoActionRunner("acme", "api", 3)
+	assert.NoError(t, err)
+	_, err = c.DeleteOrgActionRunner("acme", 3)
+	assert.NoError(t, err)
+	_, err = c.AdminDeleteActionRunner(3)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"/api/v1/repos/acme/api/actions/runners/registration-token",
+		"/api/v1/orgs/acme/actions/runners/registration-token",
+		"/api/v1/admin/runners/registration-token",
+		"/api/v1/orgs/acme/actions/runners",
+		"/api/v1/repos/acme/api/actions/runners",
+		"/api/v1/admin/actions/runners",
+		"/api/v1/repos/acme/api/actions/runners/3",
+		"/api/v1/orgs/acme/actions/runners/3",
+		"/api/v1/admin/actions/runners/3",
+		"DELETE /api/v1/repos/acme/api/actions/runners/3",
+		"DELETE /api/v1/orgs/acme/actions/runners/3",
+		"DELETE /api/v1/admin/actions/runners/3",
+	}, requests)
+
+	// registration tokens are older than the runner api
+	c, err = NewClient(server.URL, SetGiteaVersion("1.22.0"))
+	assert.NoError(t, err)
+	_, _, err = c.GetRepoRunnerRegistrationToken("acme", "api")
+	assert.NoError(t, err)
+	_, _, err = c.AdminListActionRunners(ListActionRunnersOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// SecretTarget is a repository, an organization if Repo is empty,
+// or the authenticated user if Owner is empty too, whose secret is set
+type SecretTarget struct {
+	Owner string
+	Repo  string
+}
+
+// String returns "owner/repo" for repositories, "owner" for organizations and "user" for the authenticated user
+func (t SecretTarget) String() string {
+	switch {
+	case t.Owner == "":
+		return "user"
+	case t.Repo == "":
+		return t.Owner
+	}
+	return t.Owner + "/" + t.Repo
+}
+
+// SecretRotation is the outcome of setting a secret of a target
+type SecretRotation struct {
+	Target SecretTarget
+	// Created reports whether the secret did not exist before
+	Created bool
+	Err     error
+}
+
+// String describes the outcome, e.g. "acme/api: updated"
+func (r *SecretRotation) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s: %v", r.Target, r.Err)
+	case r.Created:
+		return fmt.Sprintf("%s: created", r.Target)
+	}
+	return fmt.Sprintf("%s: updated", r.Target)
+}
+
+// RotateActionSecretOptions options for RotateActionSecret
+type RotateActionSecretOptions struct {
+	// Concurrency is the number of targets updated at once, defaults to 4
+	Concurrency int
+}
+
+// RotateActionSecret creates or updates a Gitea Actions secret of every target, e.g. to replace
+// a leaked secret everywhere it is used. Targets failing do not stop the others,
+// it returns a result per target in the order of the targets.
+// See OrgRepoSecretTargets for the targets of all repositories of organizations.
+func (c *Client) RotateActionSecret(ctx context.Context, targets []SecretTarget, secret CreateSecretOption, opt RotateActionSecretOptions) []*SecretRotation {
+	results := make([]*SecretRotation, len(targets))
+	if err := (&secret).Validate(); err != nil {
+		for i, target := range targets {
+			results[i] = &SecretRotation{Target: target, Err: err}
+		}
+		return results
+	}
+	concurrency := opt.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	client := c.WithContext(ctx)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = &SecretRotation{Target: target, Err: ctx.Err()}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = client.setActionSecret(target, secret)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// OrgRepoSecretTargets returns a SecretTarget for every repository of the organizations
+func (c *Client) OrgRepoSecretTargets(ctx context.Context, orgs ...string) ([]SecretTarget, error) {
+	var targets []SecretTarget
+	c = c.WithContext(ctx)
+	for _, org := range orgs {
+		repos, err := c.listAllOrgRepos(ctx, org)
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range repos {
+			targets = append(targets, SecretTarget{Owner: org, Repo: repo.Name})
+		}
+	}
+	return targets, nil
+}
+
+func (c *Client) setActionSecret(target SecretTarget, secret CreateSecretOption) *SecretRotation {
+	var resp *Response
+	var err error
+	switch {
+	case target.Owner == "":
+		resp, err = c.CreateUserActionSecret(secret)
+	case target.Repo == "":
+		resp, err = c.CreateOrgActionSecret(target.Owner, secret)
+	default:
+		resp, err = c.CreateRepoActionSecret(target.Owner, target.Repo, secret)
+	}
+	return &SecretRotation{
+		Target:  target,
+		Created: err == nil && resp != nil && resp.StatusCode == http.StatusCreated,
+		Err:     err,
+	}
+}
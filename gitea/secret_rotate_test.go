@@ -0,0 +1,90 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+	"code.gitea.io/sdk/gitea/giteatest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateActionSecret(t *testing.T) {
+	srv := giteatest.NewServer()
+	defer srv.Close()
+	srv.SeedUser("alice")
+	srv.SeedUser("bob")
+	srv.SeedOrg("alice", "acme")
+	srv.SeedRepo("acme", gitea.CreateRepoOption{Name: "api"})
+	srv.SeedRepo("acme", gitea.CreateRepoOption{Name: "web"})
+	srv.SeedRepo("bob", gitea.CreateRepoOption{Name: "private"})
+	c := srv.Client("alice")
+	ctx := context.Background()
+
+	resp, err := c.CreateRepoActionSecret("acme", "api", gitea.CreateSecretOption{Name: "deploy_key", Data: "leaked"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	repoTargets, err := c.OrgRepoSecretTargets(ctx, "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, []gitea.SecretTarget{{Owner: "acme", Repo: "api"}, {Owner: "acme", Repo: "web"}}, repoTargets)
+	targets := append([]gitea.SecretTarget{{Owner: "acme"}, {}, {Owner: "bob", Repo: "private"}}, repoTargets...)
+
+	results := c.RotateActionSecret(ctx, targets, gitea.CreateSecretOption{Name: "deploy_key", Data: "fresh"}, gitea.RotateActionSecretOptions{Concurrency: 2})
+	if assert.Len(t, results, 5) {
+		assert.Equal(t, "acme: created", results[0].String())
+		assert.Equal(t, "user: created", results[1].String())
+		assert.ErrorIs(t, results[2].Err, gitea.ErrForbidden)
+		assert.Equal(t, "acme/api: updated", results[3].String())
+		assert.Equal(t, "acme/web: created", results[4].String())
+	}
+	for _, owner := range []string{"acme", "alice"} {
+		data, ok := srv.ActionSecret(owner, "", "DEPLOY_KEY")
+		assert.True(t, ok, owner)
+		assert.Equal(t, "fresh", data, owner)
+	}
+	data, _ := srv.ActionSecret("acme", "api", "DEPLOY_KEY")
+	assert.Equal(t, "fresh", data)
+
+	results = c.RotateActionSecret(ctx, targets[:2], gitea.CreateSecretOption{Name: "deploy_key"}, gitea.RotateActionSecretOptions{})
+	assert.EqualError(t, results[1].Err, "data required")
+
+	secrets, _, err := c.ListRepoActionSecret("acme", "web", gitea.ListRepoActionSecretOption{})
+	assert.NoError(t, err)
+	if assert.Len(t, secrets, 1) {
+		assert.Equal(t, "DEPLOY_KEY", secrets[0].Name)
+		assert.Empty(t, secrets[0].Data)
+	}
+	_, err = c.DeleteRepoActionSecret("acme", "web", "deploy_key")
+	assert.NoError(t, err)
+	_, err = c.DeleteOrgActionSecret("acme", "deploy_key")
+	assert.NoError(t, err)
+	_, err = c.DeleteUserActionSecret("deploy_key")
+	assert.NoError(t, err)
+	_, err = c.DeleteUserActionSecret("deploy_key")
+	assert.ErrorIs(t, err, gitea.ErrNotFound)
+	_, ok := srv.ActionSecret("acme", "web", "DEPLOY_KEY")
+	assert.False(t, ok)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	results = c.RotateActionSecret(canceled, targets, gitea.CreateSecretOption{Name: "token", Data: "x"}, gitea.RotateActionSecretOptions{Concurrency: 1})
+	for _, result := range results {
+		assert.ErrorIs(t, result.Err, context.Canceled, result.Target.String())
+	}
+
+	srv.SetVersion("1.21.0")
+	old := srv.Client("alice")
+	_, err = old.CreateUserActionSecret(gitea.CreateSecretOption{Name: "token", Data: "x"})
+	assert.ErrorIs(t, err, &gitea.ErrServerVersionTooOld{})
+	_, err = old.DeleteRepoActionSecret("acme", "web", "deploy_key")
+	assert.ErrorIs(t, err, &gitea.ErrServerVersionTooOld{})
+	_, err = old.DeleteOrgActionSecret("acme", "deploy_key")
+	assert.ErrorIs(t, err, &gitea.ErrServerVersionTooOld{})
+}
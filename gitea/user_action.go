@@ -0,0 +1,132 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// CreateUserActionSecret creates or updates a secret of the authenticated user in the Gitea Actions,
+// it is available to the workflows of all repositories of the user
+func (c *Client) CreateUserActionSecret(opt CreateSecretOption) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := (&opt).Validate(); err != nil {
+		return nil, err
+	}
+	name := opt.Name
+	if err := escapeValidatePathSegments(&name); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&opt)
+	if err != nil {
+		return nil, err
+	}
+
+	_, resp, err := c.getResponse("PUT", fmt.Sprintf("/user/actions/secrets/%s", name), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
+
+// DeleteUserActionSecret deletes a secret of the authenticated user in the Gitea Actions
+func (c *Client) DeleteUserActionSecret(secretName string) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&secretName); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/user/actions/secrets/%s", secretName), jsonHeader, nil)
+	return resp, err
+}
+
+// ListUserActionVariableOption list UserActionVariable options
+type ListUserActionVariableOption struct {
+	ListOptions
+}
+
+// ListUserActionVariable list the variables of the authenticated user
+func (c *Client) ListUserActionVariable(opt ListUserActionVariableOption) ([]*Variable, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, nil, err
+	}
+	opt.setDefaults()
+	variables := make([]*Variable, 0, opt.PageSize)
+
+	link, _ := url.Parse("/user/actions/variables")
+	link.RawQuery = opt.getURLQuery().Encode()
+	resp, err := c.getParsedResponse("GET", link.String(), jsonHeader, nil, &variables)
+	return variables, resp, err
+}
+
+// GetUserActionVariable get a variable of the authenticated user
+func (c *Client) GetUserActionVariable(name string) (*Variable, *Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, nil, err
+	}
+	if err := escapeValidatePathSegments(&name); err != nil {
+		return nil, nil, err
+	}
+	variable := new(Variable)
+	resp, err := c.getParsedResponse("GET", fmt.Sprintf("/user/actions/variables/%s", name), jsonHeader, nil, variable)
+	return variable, resp, err
+}
+
+// CreateUserActionVariable creates a variable of the authenticated user in the Gitea Actions,
+// it is available to the workflows of all repositories of the user
+func (c *Client) CreateUserActionVariable(opt CreateVariableOption) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := (&opt).Validate(); err != nil {
+		return nil, err
+	}
+	name := opt.Name
+	if err := escapeValidatePathSegments(&name); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&opt)
+	if err != nil {
+		return nil, err
+	}
+
+	_, resp, err := c.getResponse("POST", fmt.Sprintf("/user/actions/variables/%s", name), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
+
+// UpdateUserActionVariable updates the value, and optionally the name, of a variable of the authenticated user
+func (c *Client) UpdateUserActionVariable(name string, opt UpdateVariableOption) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := (&opt).Validate(); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&name); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&opt)
+	if err != nil {
+		return nil, err
+	}
+
+	_, resp, err := c.getResponse("PUT", fmt.Sprintf("/user/actions/variables/%s", name), jsonHeader, bytes.NewReader(body))
+	return resp, err
+}
+
+// DeleteUserActionVariable deletes a variable of the authenticated user
+func (c *Client) DeleteUserActionVariable(name string) (*Response, error) {
+	if err := c.checkServerVersionGreaterThanOrEqual(version1_22_0); err != nil {
+		return nil, err
+	}
+	if err := escapeValidatePathSegments(&name); err != nil {
+		return nil, err
+	}
+	_, resp, err := c.getResponse("DELETE", fmt.Sprintf("/user/actions/variables/%s", name), jsonHeader, nil)
+	return resp, err
+}
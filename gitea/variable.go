@@ -0,0 +1,78 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Variable is a Gitea Actions configuration variable, available to workflows as vars.NAME
+type Variable struct {
+	// the owner of the variable, 0 for repository variables
+	OwnerID int64 `json:"owner_id"`
+	// the repository of the variable, 0 for organization and user variables
+	RepoID int64 `json:"repo_id"`
+	// the variable's name, Gitea stores it upper cased
+	Name string `json:"name"`
+	// the variable's value
+	Data string `json:"data"`
+}
+
+// variableNamePattern is the pattern Gitea checks variable names with
+var variableNamePattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// validateVariableName checks a variable name like Gitea does,
+// names starting with GITEA_ or GITHUB_ are reserved
+func validateVariableName(name string) error {
+	if len(name) == 0 {
+		return fmt.Errorf("name required")
+	}
+	if !variableNamePattern.MatchString(name) {
+		return fmt.Errorf("name %q must only contain letters, digits and underscores and must not start with a digit", name)
+	}
+	upper := strings.ToUpper(name)
+	if strings.HasPrefix(upper, "GITEA_") || strings.HasPrefix(upper, "GITHUB_") {
+		return fmt.Errorf("name %q must not start with GITEA_ or GITHUB_", name)
+	}
+	return nil
+}
+
+// CreateVariableOption represents the options for creating a variable.
+type CreateVariableOption struct {
+	Name  string `json:"name"`  // Name is the name of the variable.
+	Value string `json:"value"` // Value is the value of the variable.
+}
+
+// Validate checks if the CreateVariableOption is valid.
+func (opt *CreateVariableOption) Validate() error {
+	if err := validateVariableName(opt.Name); err != nil {
+		return err
+	}
+	if len(opt.Value) == 0 {
+		return fmt.Errorf("value required")
+	}
+	return nil
+}
+
+// UpdateVariableOption represents the options for updating a variable.
+type UpdateVariableOption struct {
+	Name  string `json:"name,omitempty"` // Name renames the variable, if set.
+	Value string `json:"value"`          // Value is the new value of the variable.
+}
+
+// Validate checks if the UpdateVariableOption is valid.
+func (opt *UpdateVariableOption) Validate() error {
+	if len(opt.Name) != 0 {
+		if err := validateVariableName(opt.Name); err != nil {
+			return err
+		}
+	}
+	if len(opt.Value) == 0 {
+		return fmt.Errorf("value required")
+	}
+	return nil
+}
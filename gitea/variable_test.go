@@ -0,0 +1,93 @@
+// Copyright 2024 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitea_test
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+	"code.gitea.io/sdk/gitea/giteatest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariableOptionValidate(t *testing.T) {
+	assert.NoError(t, (&gitea.CreateVariableOption{Name: "deploy_env", Value: "prod"}).Validate())
+	assert.EqualError(t, (&gitea.CreateVariableOption{Value: "prod"}).Validate(), "name required")
+	assert.Error(t, (&gitea.CreateVariableOption{Name: "1st", Value: "prod"}).Validate())
+	assert.Error(t, (&gitea.CreateVariableOption{Name: "deploy-env", Value: "prod"}).Validate())
+	assert.Error(t, (&gitea.CreateVariableOption{Name: "gitea_token", Value: "prod"}).Validate())
+	assert.EqualError(t, (&gitea.CreateVariableOption{Name: "deploy_env"}).Validate(), "value required")
+	assert.NoError(t, (&gitea.UpdateVariableOption{Value: "prod"}).Validate())
+	assert.Error(t, (&gitea.UpdateVariableOption{Name: "GITHUB_SHA", Value: "prod"}).Validate())
+}
+
+func TestActionVariables(t *testing.T) {
+	srv := giteatest.NewServer()
+	defer srv.Close()
+	srv.SeedUser("alice")
+	srv.SeedOrg("alice", "acme")
+	srv.SeedRepo("acme", gitea.CreateRepoOption{Name: "api"})
+	c := srv.Client("alice")
+
+	// repository
+	resp, err := c.CreateRepoActionVariable("acme", "api", gitea.CreateVariableOption{Name: "deploy_env", Value: "staging"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	_, err = c.CreateRepoActionVariable("acme", "api", gitea.CreateVariableOption{Name: "DEPLOY_ENV", Value: "staging"})
+	assert.ErrorIs(t, err, gitea.ErrConflict)
+	_, err = c.UpdateRepoActionVariable("acme", "api", "deploy_env", gitea.UpdateVariableOption{Name: "environment", Value: "prod"})
+	assert.NoError(t, err)
+	v, _, err := c.GetRepoActionVariable("acme", "api", "environment")
+	assert.NoError(t, err)
+	assert.Equal(t, "ENVIRONMENT", v.Name)
+	assert.Equal(t, "prod", v.Data)
+	assert.NotZero(t, v.RepoID)
+	vars, _, err := c.ListRepoActionVariable("acme", "api", gitea.ListRepoActionVariableOption{})
+	assert.NoError(t, err)
+	assert.Len(t, vars, 1)
+	_, err = c.DeleteRepoActionVariable("acme", "api", "environment")
+	assert.NoError(t, err)
+	_, _, err = c.GetRepoActionVariable("acme", "api", "environment")
+	assert.ErrorIs(t, err, gitea.ErrNotFound)
+
+	// organization
+	_, err = c.CreateOrgActionVariable("acme", gitea.CreateVariableOption{Name: "region", Value: "eu"})
+	assert.NoError(t, err)
+	_, err = c.UpdateOrgActionVariable("acme", "region", gitea.UpdateVariableOption{Value: "us"})
+	assert.NoError(t, err)
+	vars, _, err = c.ListOrgActionVariable("acme", gitea.ListOrgActionVariableOption{})
+	assert.NoError(t, err)
+	if assert.Len(t, vars, 1) {
+		assert.Equal(t, "REGION", vars[0].Name)
+		assert.Equal(t, "us", vars[0].Data)
+		assert.NotZero(t, vars[0].OwnerID)
+	}
+	_, err = c.DeleteOrgActionVariable("acme", "region")
+	assert.NoError(t, err)
+
+	// user
+	_, err = c.CreateUserActionVariable(gitea.CreateVariableOption{Name: "editor", Value: "vim"})
+	assert.NoError(t, err)
+	v, _, err = c.GetUserActionVariable("editor")
+	assert.NoError(t, err)
+	assert.Equal(t, "vim", v.Data)
+	_, err = c.UpdateUserActionVariable("editor", gitea.UpdateVariableOption{Value: "emacs"})
+	assert.NoError(t, err)
+	vars, _, err = c.ListUserActionVariable(gitea.ListUserActionVariableOption{})
+	assert.NoError(t, err)
+	assert.Len(t, vars, 1)
+	_, err = c.DeleteUserActionVariable("editor")
+	assert.NoError(t, err)
+
+	// invalid options are not sent
+	_, err = c.CreateUserActionVariable(gitea.CreateVariableOption{Name: "GITEA_X", Value: "x"})
+	assert.Error(t, err)
+
+	srv.SetVersion("1.21.0")
+	_, _, err = srv.Client("alice").ListOrgActionVariable("acme", gitea.ListOrgActionVariableOption{})
+	assert.Error(t, err)
+}
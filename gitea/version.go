@@ -7,6 +7,7 @@ package gitea
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/go-version"
 )
@@ -23,7 +24,8 @@ func (c *Client) ServerVersion() (string, *Response, error) {
 // CheckServerVersionConstraint validates that the login's server satisfies a
 // given version constraint such as ">= 1.11.0+dev"
 func (c *Client) CheckServerVersionConstraint(constraint string) error {
-	if err := c.loadServerVersion(); err != nil {
+	serverVersion, err := c.getServerVersion()
+	if err != nil {
 		return err
 	}
 
@@ -31,7 +33,7 @@ func (c *Client) CheckServerVersionConstraint(constraint string) error {
 	if err != nil {
 		return err
 	}
-	if !check.Check(c.serverVersion) {
+	if !check.Check(serverVersion) {
 		c.mutex.RLock()
 		url := c.url
 		c.mutex.RUnlock()
@@ -50,11 +52,13 @@ func SetGiteaVersion(v string) ClientOption {
 			return nil
 		}
 	}
-	return func(c *Client) (err error) {
-		c.getVersionOnce.Do(func() {
-			c.serverVersion, err = version.NewVersion(v)
-		})
-		return
+	return func(c *Client) error {
+		serverVersion, err := version.NewVersion(v)
+		if err != nil {
+			return err
+		}
+		c.versionCache.set(serverVersion)
+		return nil
 	}
 }
 
@@ -69,7 +73,12 @@ var (
 	version1_15_0 = version.Must(version.NewVersion("1.15.0"))
 	version1_16_0 = version.Must(version.NewVersion("1.16.0"))
 	version1_17_0 = version.Must(version.NewVersion("1.17.0"))
+	version1_19_0 = version.Must(version.NewVersion("1.19.0"))
+	version1_21_0 = version.Must(version.NewVersion("1.21.0"))
 	version1_22_0 = version.Must(version.NewVersion("1.22.0"))
+	version1_23_0 = version.Must(version.NewVersion("1.23.0"))
+	version1_24_0 = version.Must(version.NewVersion("1.24.0"))
+	version1_25_0 = version.Must(version.NewVersion("1.25.0"))
 )
 
 // ErrUnknownVersion is an unknown version from the API
@@ -87,40 +96,116 @@ func (*ErrUnknownVersion) Is(target error) bool {
 	return ok
 }
 
+// ErrServerVersionTooOld is returned for requests the server is too old for
+type ErrServerVersionTooOld struct {
+	url      string
+	required string
+}
+
+// Error fulfills error
+func (e *ErrServerVersionTooOld) Error() string {
+	return fmt.Sprintf("gitea server at %s is older than %s", e.url, e.required)
+}
+
+func (*ErrServerVersionTooOld) Is(target error) bool {
+	_, ok := target.(*ErrServerVersionTooOld)
+	return ok
+}
+
 // checkServerVersionGreaterThanOrEqual is the canonical way in the SDK to check for versions for API compatibility reasons
 func (c *Client) checkServerVersionGreaterThanOrEqual(v *version.Version) error {
 	if c.ignoreVersion {
 		return nil
 	}
-	if err := c.loadServerVersion(); err != nil {
+	serverVersion, err := c.getServerVersion()
+	if err != nil {
 		return err
 	}
 
-	if !c.serverVersion.GreaterThanOrEqual(v) {
+	if !serverVersion.GreaterThanOrEqual(v) {
 		c.mutex.RLock()
 		url := c.url
 		c.mutex.RUnlock()
-		return fmt.Errorf("gitea server at %s is older than %s", url, v.Original())
+		return &ErrServerVersionTooOld{url: url, required: v.Original()}
 	}
 	return nil
 }
 
-// loadServerVersion init the serverVersion variable
-func (c *Client) loadServerVersion() (err error) {
-	c.getVersionOnce.Do(func() {
-		raw, _, err2 := c.ServerVersion()
-		if err2 != nil {
-			err = err2
-			return
+// serverVersionCache holds the version of the server once it is known,
+// it is shared between a Client and the copies made by WithContext
+type serverVersionCache struct {
+	mutex   sync.Mutex
+	loaded  bool
+	version *version.Version
+	// loading is closed once the request for the version in flight is done
+	loading chan struct{}
+}
+
+func (v *serverVersionCache) set(serverVersion *version.Version) {
+	v.mutex.Lock()
+	v.version, v.loaded = serverVersion, true
+	v.mutex.Unlock()
+}
+
+// getServerVersion returns the version of the server, loading it if not already known
+func (c *Client) getServerVersion() (*version.Version, error) {
+	if err := c.loadServerVersion(); err != nil {
+		return nil, err
+	}
+	c.versionCache.mutex.Lock()
+	defer c.versionCache.mutex.Unlock()
+	return c.versionCache.version, nil
+}
+
+// loadServerVersion init the cached server version.
+// Only one request for the version is made at a time, calls made meanwhile wait for it
+// as long as their context allows. Request errors are not cached, so a canceled
+// context does not affect later calls.
+func (c *Client) loadServerVersion() error {
+	cache := c.versionCache
+	for {
+		cache.mutex.Lock()
+		if cache.loaded {
+			cache.mutex.Unlock()
+			return nil
+		}
+		loading := cache.loading
+		if loading == nil {
+			break
 		}
-		if c.serverVersion, err = version.NewVersion(raw); err != nil {
-			if strings.TrimSpace(raw) != "" {
-				// Version was something, just not recognized
-				c.serverVersion = version1_11_0
-				err = &ErrUnknownVersion{raw: raw}
-			}
-			return
+		cache.mutex.Unlock()
+
+		c.mutex.RLock()
+		ctx := c.ctx
+		c.mutex.RUnlock()
+		select {
+		case <-loading:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-	})
-	return
+	}
+	loading := make(chan struct{})
+	cache.loading = loading
+	cache.mutex.Unlock()
+
+	raw, _, err := c.ServerVersion()
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.loading = nil
+	close(loading)
+	if err != nil {
+		return err
+	}
+	serverVersion, err := version.NewVersion(raw)
+	if err != nil {
+		if strings.TrimSpace(raw) != "" {
+			// Version was something, just not recognized
+			cache.version, cache.loaded = version1_11_0, true
+			return &ErrUnknownVersion{raw: raw}
+		}
+		return err
+	}
+	cache.version, cache.loaded = serverVersion, true
+	return nil
 }
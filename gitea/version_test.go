@@ -5,7 +5,11 @@
 package gitea
 
 import (
+	"context"
 	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,8 +25,8 @@ func TestVersion(t *testing.T) {
 	assert.NoError(t, c.checkServerVersionGreaterThanOrEqual(version1_11_0))
 	assert.Error(t, c.CheckServerVersionConstraint("< 1.11.0"))
 
-	c.serverVersion = version1_11_0
-	assert.Error(t, c.checkServerVersionGreaterThanOrEqual(version1_15_0))
+	c.versionCache.set(version1_11_0)
+	assert.ErrorIs(t, c.checkServerVersionGreaterThanOrEqual(version1_15_0), &ErrServerVersionTooOld{})
 	c.ignoreVersion = true
 	assert.NoError(t, c.checkServerVersionGreaterThanOrEqual(version1_15_0))
 
@@ -30,3 +34,45 @@ func TestVersion(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, c.CheckServerVersionConstraint("=1.12.123"))
 }
+
+func TestServerVersionTooOld(t *testing.T) {
+	c, err := NewClient("https://gitea.example.com", SetGiteaVersion("1.21.4"))
+	assert.NoError(t, err)
+	assert.NoError(t, c.checkServerVersionGreaterThanOrEqual(version1_21_0))
+	err = c.checkServerVersionGreaterThanOrEqual(version1_22_0)
+	assert.ErrorIs(t, err, &ErrServerVersionTooOld{})
+	assert.NotErrorIs(t, err, &ErrUnknownVersion{})
+	assert.EqualError(t, err, "gitea server at https://gitea.example.com is older than 1.22.0")
+}
+
+func TestLoadServerVersionWaiters(t *testing.T) {
+	var requests atomic.Int32
+	received, release := make(chan struct{}), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			close(received)
+			<-release
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version":"1.22.3"}`))
+	}))
+	defer srv.Close()
+
+	// skip loading the version in NewClient
+	c, err := NewClient(srv.URL, SetGiteaVersion(""))
+	assert.NoError(t, err)
+	c.ignoreVersion = false
+	loaded := make(chan error)
+	go func() { loaded <- c.loadServerVersion() }()
+	<-received
+
+	// a waiter gives up with its own context while the request is in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, c.WithContext(ctx).checkServerVersionGreaterThanOrEqual(version1_22_0), context.Canceled)
+
+	close(release)
+	assert.NoError(t, <-loaded)
+	assert.NoError(t, c.checkServerVersionGreaterThanOrEqual(version1_22_0))
+	assert.EqualValues(t, 1, requests.Load())
+}